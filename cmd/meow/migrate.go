@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dickeyy/meow/internal/config"
+	"github.com/dickeyy/meow/internal/storage"
+)
+
+const migrateUsage = "Usage: meow migrate up|down|status"
+
+// runMigrate handles `meow migrate`, returning the exit code
+func runMigrate(args []string) int {
+	if len(args) != 1 {
+		fmt.Println(migrateUsage)
+		return 2
+	}
+
+	url, err := config.LoadPostgresURL()
+	if err != nil {
+		fmt.Printf("Failed to load config: %v\n", err)
+		return 1
+	}
+
+	store, err := storage.OpenPostgresStore(context.Background(), url)
+	if err != nil {
+		fmt.Printf("Failed to connect to postgres: %v\n", err)
+		return 1
+	}
+	defer store.Close()
+
+	switch args[0] {
+	case "up":
+		err = migrateUp(store)
+	case "down":
+		err = migrateDown(store)
+	case "status":
+		err = migrateStatus(store)
+	default:
+		fmt.Println(migrateUsage)
+		return 2
+	}
+
+	if err != nil {
+		fmt.Printf("Migration failed: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func migrateUp(store *storage.PostgresStore) error {
+	applied, err := store.MigrateUp()
+	for _, m := range applied {
+		fmt.Printf("Applied %04d_%s\n", m.Version, m.Name)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(applied) == 0 {
+		fmt.Println("Already up to date")
+	}
+	return nil
+}
+
+func migrateDown(store *storage.PostgresStore) error {
+	m, err := store.MigrateDown()
+	if err != nil {
+		return err
+	}
+
+	if m == nil {
+		fmt.Println("No migrations to roll back")
+		return nil
+	}
+	fmt.Printf("Rolled back %04d_%s\n", m.Version, m.Name)
+	return nil
+}
+
+func migrateStatus(store *storage.PostgresStore) error {
+	statuses, err := store.MigrationStatus()
+	if err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		applied := "pending"
+		if status.AppliedAt != nil {
+			applied = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%04d_%-24s %s\n", status.Version, status.Name, applied)
+	}
+	return nil
+}
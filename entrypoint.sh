@@ -7,5 +7,5 @@ if [ -f /app/cookies-source.txt ]; then
 fi
 
 # Run the bot
-exec /app/meow
+exec /app/meow "$@"
 
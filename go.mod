@@ -24,3 +24,5 @@ require (
 	golang.org/x/sys v0.25.0 // indirect
 	golang.org/x/text v0.18.0 // indirect
 )
+
+replace layeh.com/gopus => ./third_party/gopus
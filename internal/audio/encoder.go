@@ -0,0 +1,105 @@
+package audio
+
+import (
+	"strings"
+
+	"layeh.com/gopus"
+)
+
+// Application tunes the Opus encoder for a kind of audio
+type Application string
+
+const (
+	ApplicationVoIP     Application = "voip"
+	ApplicationAudio    Application = "audio"
+	ApplicationLowDelay Application = "lowdelay"
+)
+
+var Applications = []Application{ApplicationAudio, ApplicationVoIP, ApplicationLowDelay}
+
+var applicationNames = map[Application]string{
+	ApplicationVoIP:     "VoIP",
+	ApplicationAudio:    "Audio",
+	ApplicationLowDelay: "Low delay",
+}
+
+func (a Application) String() string {
+	if name, ok := applicationNames[a]; ok {
+		return name
+	}
+	return string(a)
+}
+
+func ParseApplication(s string) (Application, bool) {
+	a := Application(strings.ToLower(strings.TrimSpace(s)))
+	_, ok := applicationNames[a]
+	return a, ok
+}
+
+func (a Application) opus() gopus.Application {
+	switch a {
+	case ApplicationVoIP:
+		return gopus.Voip
+	case ApplicationLowDelay:
+		return gopus.RestrictedLowDelay
+	default:
+		return gopus.Audio
+	}
+}
+
+// Opus bitrates in bits per second
+const (
+	MinBitrate     = 8000
+	MaxBitrate     = 510000
+	DefaultBitrate = 128000
+)
+
+// The expected packet loss is a percentage
+const MaxPacketLoss = 100
+
+// EncoderSettings control how PCM is encoded before it is sent to Discord
+type EncoderSettings struct {
+	Bitrate     int // Bits per second, 0 to match the voice channel
+	Application Application
+	FEC         bool // In-band forward error correction, only used when PacketLoss is above 0
+	PacketLoss  int  // Expected packet loss in percent, traded against quality
+}
+
+func DefaultEncoderSettings() EncoderSettings {
+	return EncoderSettings{Application: ApplicationAudio}
+}
+
+// bitrate is the bitrate to encode at in a channel that allows up to
+// channelBitrate, which is 0 if unknown. There is no point going over the
+// channel's limit since Discord won't deliver the extra quality.
+func (e EncoderSettings) bitrate(channelBitrate int) int {
+	bitrate := e.Bitrate
+	if bitrate == 0 {
+		bitrate = channelBitrate
+	} else if channelBitrate > 0 && channelBitrate < bitrate {
+		bitrate = channelBitrate
+	}
+
+	if bitrate == 0 {
+		return DefaultBitrate
+	}
+	return max(MinBitrate, min(MaxBitrate, bitrate))
+}
+
+// newEncoder creates an Opus encoder with these settings at bitrate
+func (e EncoderSettings) newEncoder(bitrate int) (*gopus.Encoder, error) {
+	encoder, err := gopus.NewEncoder(sampleRate, channels, e.Application.opus())
+	if err != nil {
+		return nil, err
+	}
+	encoder.SetBitrate(bitrate)
+	e.applyLoss(encoder)
+	return encoder, nil
+}
+
+// applyLoss sets FEC and the expected packet loss, which unlike the
+// application can be changed on an encoder that is already in use
+func (e EncoderSettings) applyLoss(encoder *gopus.Encoder) {
+	encoder.SetInbandFEC(e.FEC)
+	encoder.SetPacketLossPerc(max(0, min(MaxPacketLoss, e.PacketLoss)))
+}
@@ -0,0 +1,111 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	EQBandCount = 10
+	MaxEQGain   = 12.0 // dB either way
+
+	// Bandwidth of each peaking filter, roughly one octave
+	eqQ = 1.41
+)
+
+// EQFrequencies are the centre frequencies of the graphic EQ bands in Hz
+var EQFrequencies = [EQBandCount]float64{31, 62, 125, 250, 500, 1000, 2000, 4000, 8000, 16000}
+
+// EQBands holds the gain of each band in dB
+type EQBands [EQBandCount]float64
+
+func (b EQBands) IsFlat() bool {
+	for _, gain := range b {
+		if gain != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// FormatEQFrequency renders a band's centre frequency, e.g. "125 Hz" or "4 kHz"
+func FormatEQFrequency(band int) string {
+	freq := EQFrequencies[band]
+	if freq >= 1000 {
+		return fmt.Sprintf("%g kHz", freq/1000)
+	}
+	return fmt.Sprintf("%g Hz", freq)
+}
+
+// biquad is a single peaking filter with independent state per channel
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	x1, x2, y1, y2     [channels]float64
+}
+
+func newPeakingFilter(freq, gainDB float64) *biquad {
+	a := math.Pow(10, gainDB/40)
+	w0 := 2 * math.Pi * freq / sampleRate
+	alpha := math.Sin(w0) / (2 * eqQ)
+	cosW0 := math.Cos(w0)
+
+	a0 := 1 + alpha/a
+	return &biquad{
+		b0: (1 + alpha*a) / a0,
+		b1: (-2 * cosW0) / a0,
+		b2: (1 - alpha*a) / a0,
+		a1: (-2 * cosW0) / a0,
+		a2: (1 - alpha/a) / a0,
+	}
+}
+
+func (f *biquad) process(x float64, ch int) float64 {
+	y := f.b0*x + f.b1*f.x1[ch] + f.b2*f.x2[ch] - f.a1*f.y1[ch] - f.a2*f.y2[ch]
+	f.x2[ch], f.x1[ch] = f.x1[ch], x
+	f.y2[ch], f.y1[ch] = f.y1[ch], y
+	return y
+}
+
+// equalizer applies the session's EQ bands to PCM frames in-process, so
+// band changes are heard without restarting ffmpeg
+type equalizer struct {
+	bands   EQBands
+	filters []*biquad
+}
+
+func newEqualizer() *equalizer {
+	return &equalizer{}
+}
+
+func (e *equalizer) apply(pcm []int16, bands EQBands) {
+	if bands != e.bands {
+		e.configure(bands)
+	}
+
+	if len(e.filters) == 0 {
+		return
+	}
+
+	samples := len(pcm) / channels
+	for i := 0; i < samples; i++ {
+		for c := 0; c < channels; c++ {
+			idx := i*channels + c
+			v := float64(pcm[idx])
+			for _, f := range e.filters {
+				v = f.process(v, c)
+			}
+			pcm[idx] = clampSample(v)
+		}
+	}
+}
+
+// configure rebuilds the filters for bands, skipping any left at 0 dB
+func (e *equalizer) configure(bands EQBands) {
+	e.bands = bands
+	e.filters = e.filters[:0]
+	for i, gain := range bands {
+		if gain != 0 {
+			e.filters = append(e.filters, newPeakingFilter(EQFrequencies[i], gain))
+		}
+	}
+}
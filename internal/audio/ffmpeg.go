@@ -0,0 +1,180 @@
+package audio
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"time"
+)
+
+// FFmpegSource wraps an ffmpeg process that turns a stream URL or file into
+// raw 48kHz stereo s16le PCM, optionally through an -af filter chain
+type FFmpegSource struct {
+	url    string
+	af     string
+	cmd    *exec.Cmd
+	stdout io.ReadCloser
+	buf    []byte
+}
+
+func NewFFmpegSource(url string, offset time.Duration, af string) (*FFmpegSource, error) {
+	s := &FFmpegSource{
+		url: url,
+		af:  af,
+		buf: make([]byte, maxBytes),
+	}
+	if err := s.start(offset); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FFmpegSource) start(offset time.Duration) error {
+	var output []string
+	if s.af != "" {
+		output = append(output, "-af", s.af)
+	}
+	output = append(output,
+		"-f", "s16le",
+		"-ar", "48000",
+		"-ac", "2",
+	)
+
+	cmd, stdout, err := startFFmpeg(s.url, offset, output...)
+	if err != nil {
+		return err
+	}
+
+	s.cmd = cmd
+	s.stdout = stdout
+	return nil
+}
+
+func (s *FFmpegSource) ReadFrame(pcm []int16) error {
+	if _, err := io.ReadFull(s.stdout, s.buf); err != nil {
+		return err
+	}
+	decodePCM(pcm, s.buf)
+	return nil
+}
+
+// Seek restarts ffmpeg at position with the same input and filters
+func (s *FFmpegSource) Seek(position time.Duration) error {
+	s.Close()
+	return s.start(position)
+}
+
+func (s *FFmpegSource) Close() error {
+	killFFmpeg(s.cmd)
+	s.cmd = nil
+	return nil
+}
+
+// FFmpegOpusSource remuxes an Opus stream into Ogg without decoding it, so
+// its packets can go straight to Discord
+type FFmpegOpusSource struct {
+	url     string
+	cmd     *exec.Cmd
+	packets *oggReader
+}
+
+// OpenFFmpegOpus is the default PacketOpener. It fails if the track's
+// stream isn't Opus.
+func OpenFFmpegOpus(track *Track, offset time.Duration) (PacketSource, error) {
+	if track.StreamURL() == "" {
+		return nil, fmt.Errorf("track has no stream URL")
+	}
+	return NewFFmpegOpusSource(track.StreamURL(), offset)
+}
+
+func NewFFmpegOpusSource(url string, offset time.Duration) (*FFmpegOpusSource, error) {
+	s := &FFmpegOpusSource{url: url}
+	if err := s.start(offset); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FFmpegOpusSource) start(offset time.Duration) error {
+	cmd, stdout, err := startFFmpeg(s.url, offset,
+		"-map", "0:a:0",
+		"-c:a", "copy",
+		"-f", "ogg",
+	)
+	if err != nil {
+		return err
+	}
+
+	// ffmpeg happily copies other codecs into Ogg too, so check what
+	// actually came out before committing to it
+	packets := newOggReader(stdout)
+	if err := readOpusHeaders(packets); err != nil {
+		killFFmpeg(cmd)
+		return fmt.Errorf("failed to read opus stream: %w", err)
+	}
+
+	s.cmd = cmd
+	s.packets = packets
+	return nil
+}
+
+func (s *FFmpegOpusSource) ReadPacket() ([]byte, error) {
+	return s.packets.next()
+}
+
+// Seek restarts ffmpeg at position. Without decoding ffmpeg can only cut on
+// packet boundaries, which is close enough.
+func (s *FFmpegOpusSource) Seek(position time.Duration) error {
+	s.Close()
+	return s.start(position)
+}
+
+func (s *FFmpegOpusSource) Close() error {
+	killFFmpeg(s.cmd)
+	s.cmd = nil
+	return nil
+}
+
+// startFFmpeg runs ffmpeg on url from offset, writing to stdout in the
+// format described by output
+func startFFmpeg(url string, offset time.Duration, output ...string) (*exec.Cmd, io.ReadCloser, error) {
+	args := []string{
+		"-reconnect", "1",
+		"-reconnect_streamed", "1",
+		"-reconnect_delay_max", "5",
+	}
+
+	// Seeking before -i lets ffmpeg jump straight to the offset instead of
+	// decoding everything up to it
+	if offset > 0 {
+		args = append(args, "-ss", fmt.Sprintf("%.3f", offset.Seconds()))
+	}
+
+	args = append(args, "-i", url)
+	args = append(args, output...)
+	args = append(args,
+		"-loglevel", "warning",
+		"pipe:1",
+	)
+
+	cmd := exec.Command("ffmpeg", args...)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get ffmpeg stdout: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, nil, fmt.Errorf("failed to start ffmpeg: %w", err)
+	}
+
+	return cmd, stdout, nil
+}
+
+func killFFmpeg(cmd *exec.Cmd) {
+	if cmd == nil {
+		return
+	}
+	cmd.Process.Kill()
+	cmd.Wait()
+}
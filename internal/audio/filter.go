@@ -0,0 +1,127 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+type Filter string
+
+const (
+	FilterBassBoost Filter = "bassboost"
+	FilterNightcore Filter = "nightcore"
+	FilterVaporwave Filter = "vaporwave"
+	Filter8D        Filter = "8d"
+	FilterKaraoke   Filter = "karaoke"
+)
+
+const (
+	MinSpeed = 0.5
+	MaxSpeed = 2.0
+	MaxPitch = 12.0 // semitones either way
+)
+
+// Filters lists every preset in the order they are applied
+var Filters = []Filter{
+	FilterBassBoost,
+	FilterNightcore,
+	FilterVaporwave,
+	Filter8D,
+	FilterKaraoke,
+}
+
+var filterNames = map[Filter]string{
+	FilterBassBoost: "Bass Boost",
+	FilterNightcore: "Nightcore",
+	FilterVaporwave: "Vaporwave",
+	Filter8D:        "8D",
+	FilterKaraoke:   "Karaoke",
+}
+
+// ffmpeg -af fragments for each preset. The rate-changing presets resample
+// first so asetrate always starts from 48kHz, whatever the source rate.
+var filterChains = map[Filter]string{
+	FilterBassBoost: "bass=g=8:f=110:w=0.6",
+	FilterNightcore: "aresample=48000,asetrate=48000*1.25,aresample=48000",
+	FilterVaporwave: "aresample=48000,asetrate=48000*0.8,aresample=48000",
+	Filter8D:        "apulsator=hz=0.08",
+	FilterKaraoke:   "stereotools=mlev=0.03",
+}
+
+// How much the rate-changing presets speed playback up or slow it down
+var filterRates = map[Filter]float64{
+	FilterNightcore: 1.25,
+	FilterVaporwave: 0.8,
+}
+
+func (f Filter) String() string {
+	if name, ok := filterNames[f]; ok {
+		return name
+	}
+	return string(f)
+}
+
+func ParseFilter(s string) (Filter, bool) {
+	for _, f := range Filters {
+		if strings.EqualFold(s, string(f)) {
+			return f, true
+		}
+	}
+	return "", false
+}
+
+// filterChain builds the ffmpeg -af argument for a set of presets plus the
+// session's speed and pitch
+func filterChain(filters []Filter, speed, pitch float64) string {
+	parts := make([]string, 0, len(filters)+2)
+	for _, f := range Filters {
+		for _, active := range filters {
+			if active == f {
+				parts = append(parts, filterChains[f])
+			}
+		}
+	}
+
+	// Pitch is shifted by resampling, which also changes the tempo, so the
+	// tempo filter compensates for it
+	tempo := speed
+	if pitch != 0 {
+		factor := math.Pow(2, pitch/12)
+		parts = append(parts, fmt.Sprintf("aresample=48000,asetrate=%.0f,aresample=48000", sampleRate*factor))
+		tempo /= factor
+	}
+
+	if tempo != 1 {
+		parts = append(parts, atempoChain(tempo))
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// atempoChain splits a tempo change into atempo filters that each stay in
+// the 0.5-2.0 range older ffmpeg builds support
+func atempoChain(tempo float64) string {
+	var parts []string
+	for tempo > 2 {
+		parts = append(parts, "atempo=2.0")
+		tempo /= 2
+	}
+	for tempo < 0.5 {
+		parts = append(parts, "atempo=0.5")
+		tempo /= 0.5
+	}
+	parts = append(parts, fmt.Sprintf("atempo=%.4f", tempo))
+	return strings.Join(parts, ",")
+}
+
+// filterRate is the combined playback rate change from a set of presets
+func filterRate(filters []Filter) float64 {
+	rate := 1.0
+	for _, f := range filters {
+		if r, ok := filterRates[f]; ok {
+			rate *= r
+		}
+	}
+	return rate
+}
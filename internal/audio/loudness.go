@@ -0,0 +1,176 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// TargetLoudness is the integrated loudness tracks are normalized to, in
+	// LUFS. It matches what most streaming services aim for.
+	TargetLoudness = -14.0
+
+	// Quiet tracks are boosted at most this far, and never so far that their
+	// true peak goes over truePeakCeiling, which would clip
+	maxLoudnessBoost = 6.0
+	truePeakCeiling  = -1.5
+
+	measureTimeout = 3 * time.Minute
+
+	// Tracks waiting to be measured past this many are dropped. They're
+	// queued again when they are next resolved.
+	maxPendingMeasurements = 8
+)
+
+// loudnormFilter is the single-pass ffmpeg fallback used while a track
+// hasn't been measured yet
+var loudnormFilter = fmt.Sprintf("loudnorm=I=%g:TP=%g:LRA=11", TargetLoudness, truePeakCeiling)
+
+// MeasureLoudness runs ffmpeg's EBU R128 analysis over a whole stream and
+// returns its integrated loudness in LUFS and true peak in dBTP
+func MeasureLoudness(streamURL string) (lufs, truePeak float64, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), measureTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "ffmpeg",
+		"-hide_banner",
+		"-nostats",
+		"-i", streamURL,
+		"-vn",
+		"-af", "loudnorm=print_format=json",
+		"-f", "null",
+		"-",
+	)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return 0, 0, fmt.Errorf("loudness analysis timed out after %v", measureTimeout)
+		}
+		return 0, 0, fmt.Errorf("loudness analysis failed: %w", err)
+	}
+
+	// loudnorm prints its summary as a JSON object at the end of the log
+	out := stderr.String()
+	start := strings.LastIndex(out, "{")
+	end := strings.LastIndex(out, "}")
+	if start == -1 || end < start {
+		return 0, 0, fmt.Errorf("no loudness summary in ffmpeg output")
+	}
+
+	var summary struct {
+		InputI  string `json:"input_i"`
+		InputTP string `json:"input_tp"`
+	}
+	if err := json.Unmarshal([]byte(out[start:end+1]), &summary); err != nil {
+		return 0, 0, fmt.Errorf("failed to parse loudness summary: %w", err)
+	}
+
+	lufs, err = strconv.ParseFloat(summary.InputI, 64)
+	if err != nil || math.IsInf(lufs, 0) || lufs == 0 {
+		return 0, 0, fmt.Errorf("invalid integrated loudness %q", summary.InputI)
+	}
+	truePeak, err = strconv.ParseFloat(summary.InputTP, 64)
+	if err != nil || math.IsInf(truePeak, 0) {
+		return 0, 0, fmt.Errorf("invalid true peak %q", summary.InputTP)
+	}
+
+	return lufs, truePeak, nil
+}
+
+// loudnessGain is the linear gain that brings a track measured at lufs to
+// TargetLoudness, or as close as it gets without pushing truePeak over
+// truePeakCeiling. Turning a track down can't clip it, so only boosts are
+// limited.
+func loudnessGain(lufs, truePeak float64) float64 {
+	db := TargetLoudness - lufs
+	if db > 0 {
+		db = min(db, maxLoudnessBoost, max(0, truePeakCeiling-truePeak))
+	}
+	return math.Pow(10, db/20)
+}
+
+// LoudnessMeter measures tracks one at a time in the background. Each
+// measurement downloads the whole track, so a guild never runs more than
+// one next to playback.
+type LoudnessMeter struct {
+	onMeasured func(track *Track, lufs, truePeak float64)
+
+	mu      sync.Mutex
+	pending []*Track
+	current *Track // Being measured, nil when the worker is idle
+	running bool
+}
+
+// NewLoudnessMeter returns a meter that calls onMeasured, if set, after
+// storing each measurement on its track
+func NewLoudnessMeter(onMeasured func(track *Track, lufs, truePeak float64)) *LoudnessMeter {
+	return &LoudnessMeter{onMeasured: onMeasured}
+}
+
+// Measure queues a resolved track to be measured, unless it already has
+// been or is waiting to be
+func (m *LoudnessMeter) Measure(track *Track) {
+	if track.Loudness() != 0 || track.StreamURL() == "" {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if track == m.current {
+		return
+	}
+	for _, t := range m.pending {
+		if t == track {
+			return
+		}
+	}
+	if len(m.pending) >= maxPendingMeasurements {
+		return
+	}
+
+	m.pending = append(m.pending, track)
+	if !m.running {
+		m.running = true
+		go m.run()
+	}
+}
+
+func (m *LoudnessMeter) run() {
+	for {
+		m.mu.Lock()
+		if len(m.pending) == 0 {
+			m.current = nil
+			m.running = false
+			m.mu.Unlock()
+			return
+		}
+		track := m.pending[0]
+		m.pending = m.pending[1:]
+		m.current = track
+		m.mu.Unlock()
+
+		lufs, truePeak, err := MeasureLoudness(track.StreamURL())
+		if err != nil {
+			fmt.Printf("[loudness] Failed to measure %s: %v\n", track.Title, err)
+			continue
+		}
+
+		fmt.Printf("[loudness] %s measured at %.1f LUFS, %.1f dBTP\n", track.Title, lufs, truePeak)
+		track.SetLoudness(lufs, truePeak)
+		if m.onMeasured != nil {
+			m.onMeasured(track, lufs, truePeak)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package audio
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLoudnessGain(t *testing.T) {
+	tests := []struct {
+		name     string
+		lufs     float64
+		truePeak float64
+		wantDB   float64
+	}{
+		{"loud track is turned down", -8, 0, -6},
+		{"quiet track with headroom is boosted", -18, -10, 4},
+		{"boost stops at the peak ceiling", -18, -3, 1.5},
+		{"boost stops at the max", -30, -20, maxLoudnessBoost},
+		{"peak already over the ceiling", -18, -1, 0},
+		{"unknown peak isn't boosted", -18, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := 20 * math.Log10(loudnessGain(tt.lufs, tt.truePeak))
+			if math.Abs(got-tt.wantDB) > 1e-9 {
+				t.Errorf("loudnessGain(%g, %g) = %.2f dB, want %.2f dB", tt.lufs, tt.truePeak, got, tt.wantDB)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+)
+
+// oggReader splits an Ogg stream into the packets it carries
+type oggReader struct {
+	r       *bufio.Reader
+	header  [27]byte
+	packets [][]byte
+	partial []byte // Packet continued on the next page
+}
+
+func newOggReader(r io.Reader) *oggReader {
+	return &oggReader{r: bufio.NewReader(r)}
+}
+
+// next returns the next complete packet
+func (o *oggReader) next() ([]byte, error) {
+	for len(o.packets) == 0 {
+		if err := o.readPage(); err != nil {
+			return nil, err
+		}
+	}
+
+	packet := o.packets[0]
+	o.packets = o.packets[1:]
+	return packet, nil
+}
+
+func (o *oggReader) readPage() error {
+	if _, err := io.ReadFull(o.r, o.header[:]); err != nil {
+		return err
+	}
+	if !bytes.Equal(o.header[:4], []byte("OggS")) {
+		return fmt.Errorf("lost ogg page sync")
+	}
+
+	segments := make([]byte, o.header[26])
+	if _, err := io.ReadFull(o.r, segments); err != nil {
+		return err
+	}
+
+	size := 0
+	for _, lacing := range segments {
+		size += int(lacing)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(o.r, data); err != nil {
+		return err
+	}
+
+	// A lacing value of 255 means the packet carries on in the next segment,
+	// possibly on the next page
+	packet := o.partial
+	o.partial = nil
+	for _, lacing := range segments {
+		packet = append(packet, data[:lacing]...)
+		data = data[lacing:]
+		if lacing < 255 {
+			o.packets = append(o.packets, packet)
+			packet = nil
+		}
+	}
+	o.partial = packet
+
+	return nil
+}
+
+// readOpusHeaders consumes the OpusHead and OpusTags packets at the start
+// of an Ogg Opus stream
+func readOpusHeaders(o *oggReader) error {
+	head, err := o.next()
+	if err != nil {
+		return err
+	}
+	if !bytes.HasPrefix(head, []byte("OpusHead")) {
+		return fmt.Errorf("stream is not opus")
+	}
+
+	tags, err := o.next()
+	if err != nil {
+		return err
+	}
+	if !bytes.HasPrefix(tags, []byte("OpusTags")) {
+		return fmt.Errorf("missing opus tags")
+	}
+	return nil
+}
+
+// opusPacketDuration works out how much audio an Opus packet holds from its
+// TOC byte, or 0 if the packet is malformed
+func opusPacketDuration(packet []byte) time.Duration {
+	if len(packet) == 0 {
+		return 0
+	}
+
+	toc := packet[0]
+	config := toc >> 3
+
+	var frame time.Duration
+	switch {
+	case config < 12: // SILK
+		frame = [4]time.Duration{10, 20, 40, 60}[config%4] * time.Millisecond
+	case config < 16: // Hybrid
+		frame = [2]time.Duration{10, 20}[config%2] * time.Millisecond
+	default: // CELT
+		frame = [4]time.Duration{2500, 5000, 10000, 20000}[config%4] * time.Microsecond
+	}
+
+	count := 1
+	switch toc & 3 {
+	case 1, 2:
+		count = 2
+	case 3:
+		if len(packet) < 2 {
+			return 0
+		}
+		count = int(packet[1] & 0x3f)
+	}
+
+	return frame * time.Duration(count)
+}
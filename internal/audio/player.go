@@ -1,15 +1,13 @@
 package audio
 
 import (
-	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
-	"os/exec"
 	"sync"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
-	"layeh.com/gopus"
 )
 
 const (
@@ -17,201 +15,706 @@ const (
 	sampleRate = 48000
 	frameSize  = 960 // 20ms at 48kHz
 	maxBytes   = (frameSize * channels) * 2
+
+	frameDuration = 20 * time.Millisecond
+
+	// A stream that stops more than this far short of the track's duration
+	// is treated as dropped rather than finished
+	earlyEndTolerance   = 5 * time.Second
+	maxRecoveryAttempts = 3
 )
 
 type Player struct {
-	mu sync.Mutex
+	mu      sync.Mutex
+	open    SourceOpener
+	discord *discordgo.Session // For rejoining voice after a disconnect
+
+	// Used for Opus tracks that can be sent without re-encoding, nil to
+	// always decode
+	passthrough PacketOpener
 }
 
 func NewPlayer() *Player {
-	return &Player{}
+	return &Player{
+		open:        OpenFFmpeg,
+		passthrough: OpenFFmpegOpus,
+	}
+}
+
+// NewPlayerWithSource returns a player that reads tracks through open
+// instead of ffmpeg
+func NewPlayerWithSource(open SourceOpener) *Player {
+	return &Player{open: open}
 }
 
 func (p *Player) Play(session *Session, discord *discordgo.Session) error {
+	return p.PlayFrom(session, discord, 0)
+}
+
+// PlayFrom starts the current track at offset, e.g. to pick up a session
+// saved before a restart
+func (p *Player) PlayFrom(session *Session, discord *discordgo.Session, offset time.Duration) error {
 	vc := session.VoiceConnection()
 	if vc == nil {
 		return fmt.Errorf("not connected to voice channel")
 	}
 
+	p.mu.Lock()
+	p.discord = discord
+	p.mu.Unlock()
+
 	track := session.Queue().Current()
 	if track == nil {
 		return fmt.Errorf("no track to play")
 	}
 
-	if track.StreamURL == "" {
-		return fmt.Errorf("track has no stream URL")
+	if err := p.resolve(session, track); err != nil {
+		return err
+	}
+
+	// Live streams always start from the live edge
+	if track.Duration == 0 {
+		offset = 0
 	}
 
 	session.SetState(StatePlaying)
 	session.SetStartedAt(time.Now())
+	session.SetPosition(offset)
 
 	if session.OnTrackChange != nil {
 		session.OnTrackChange(track)
 	}
 
-	go p.stream(session, track)
+	session.PrefetchUpcoming()
+
+	go p.stream(session, track, offset)
 
 	return nil
 }
 
-func (p *Player) stream(session *Session, track *Track) {
+func (p *Player) stream(session *Session, track *Track, offset time.Duration) {
 	vc := session.VoiceConnection()
 	if vc == nil {
 		return
 	}
 
 	vc.Speaking(true)
-	defer vc.Speaking(false)
-
-	volume := float64(session.Volume()) / 100.0
-
-	// Create FFmpeg command to get raw PCM audio
-	ffmpeg := exec.Command("ffmpeg",
-		"-reconnect", "1",
-		"-reconnect_streamed", "1",
-		"-reconnect_delay_max", "5",
-		"-i", track.StreamURL,
-		"-f", "s16le",
-		"-ar", "48000",
-		"-ac", "2",
-		"-af", fmt.Sprintf("volume=%.2f", volume),
-		"-loglevel", "warning",
-		"pipe:1",
-	)
-
-	stdout, err := ffmpeg.StdoutPipe()
+	defer func() {
+		// The connection may have been replaced by a rejoin
+		if vc := session.VoiceConnection(); vc != nil {
+			vc.Speaking(false)
+		}
+	}()
+
+	// Pause and rejoin if the voice connection drops mid-stream
+	watching := make(chan struct{})
+	defer close(watching)
+	go p.watchVoice(session, watching)
+
+	src, err := p.openSource(session, track, offset)
 	if err != nil {
-		fmt.Printf("Failed to get ffmpeg stdout: %v\n", err)
+		fmt.Printf("%v\n", err)
 		session.SetState(StateStopped)
 		return
 	}
 
-	if err := ffmpeg.Start(); err != nil {
-		fmt.Printf("Failed to start ffmpeg: %v\n", err)
-		session.SetState(StateStopped)
-		return
-	}
+	// In gapless mode the next track's source is opened before the current
+	// one runs out, and crossfading reads from both at once
+	var next *trackSource
+	var nextTrack *Track
+
+	// Set while the next track's source is being opened in the background
+	var pending <-chan preloaded
 
+	dropNext := func() {
+		next.close()
+		abandonPreload(pending)
+		next, nextTrack, pending = nil, nil, nil
+	}
 	defer func() {
-		ffmpeg.Process.Kill()
-		ffmpeg.Wait()
+		src.close()
+		dropNext()
 	}()
 
 	// Create Opus encoder
-	encoder, err := gopus.NewEncoder(sampleRate, channels, gopus.Audio)
+	settings := session.Encoder()
+	bitrate := session.Bitrate()
+	encoder, err := settings.newEncoder(bitrate)
 	if err != nil {
 		fmt.Printf("Failed to create opus encoder: %v\n", err)
 		session.SetState(StateStopped)
 		return
 	}
 
-	// Set encoder bitrate (128kbps for quality)
-	encoder.SetBitrate(128000)
+	// Frames are sent on a steady 20ms clock from a buffer the loop below
+	// keeps topped up
+	out := newFrameSender(session)
+	defer out.stop()
 
-	// Buffer for reading PCM data
+	// Buffers for reading PCM data
 	pcmBuffer := make([]int16, frameSize*channels)
-	byteBuffer := make([]byte, maxBytes)
+	nextBuffer := make([]int16, frameSize*channels)
+
+	// Volume is applied here rather than in ffmpeg so changes take effect
+	// on the track that is already playing
+	gain := newGainRamp(session.Gain())
+	eq := newEqualizer()
+
+	// How many times the current track's stream has been recovered
+	recoveries := 0
+
+	// For the play history
+	started := time.Now()
+	var listened time.Duration
+	played := func(skipped bool) {
+		p.played(session, track, started, listened, skipped)
+	}
 
 	for {
 		select {
 		case <-session.StopChan():
-			session.Queue().ClearAll()
-			session.SetState(StateStopped)
-			if session.OnTrackEnd != nil {
-				session.OnTrackEnd()
-			}
+			played(true)
+			p.stop(session)
 			return
 
 		case <-session.SkipChan():
-			next := session.Queue().Next()
-			if next != nil {
-				session.SetState(StateStopped)
-				go p.playNext(session, next)
-			} else {
-				session.SetState(StateStopped)
-				if session.OnTrackEnd != nil {
-					session.OnTrackEnd()
-				}
-			}
+			played(true)
+			p.advance(session, skipLoopMode(session.LoopMode()))
 			return
 
-		case <-session.PauseChan():
-			// Wait for resume or stop
-			select {
-			case <-session.ResumeChan():
-				// Continue streaming
-			case <-session.StopChan():
-				session.Queue().ClearAll()
+		case req := <-session.SeekChan():
+			// Any buffered audio and preloaded transition are stale after a seek
+			out.flush()
+			dropNext()
+
+			if src, err = p.seek(session, src, track, req); err != nil {
+				fmt.Printf("Failed to seek: %v\n", err)
 				session.SetState(StateStopped)
-				if session.OnTrackEnd != nil {
-					session.OnTrackEnd()
-				}
-				return
-			case <-session.SkipChan():
-				next := session.Queue().Next()
-				if next != nil {
-					session.SetState(StateStopped)
-					go p.playNext(session, next)
-				} else {
-					session.SetState(StateStopped)
-					if session.OnTrackEnd != nil {
-						session.OnTrackEnd()
-					}
-				}
 				return
 			}
 
-		default:
-			// Read PCM data from ffmpeg
-			n, err := io.ReadFull(stdout, byteBuffer)
-			if err != nil {
-				if err == io.EOF || err == io.ErrUnexpectedEOF {
-					// Track finished
-					next := session.Queue().Next()
-					if next != nil {
-						go p.playNext(session, next)
-					} else {
+		case <-session.PauseChan():
+			// Wait for resume or stop, seeking in place if asked to
+			paused := true
+			for paused {
+				select {
+				case <-session.ResumeChan():
+					paused = false
+				case <-session.StopChan():
+					played(true)
+					p.stop(session)
+					return
+				case <-session.SkipChan():
+					played(true)
+					p.advance(session, skipLoopMode(session.LoopMode()))
+					return
+				case req := <-session.SeekChan():
+					out.flush()
+					dropNext()
+
+					if src, err = p.seek(session, src, track, req); err != nil {
+						fmt.Printf("Failed to seek: %v\n", err)
 						session.SetState(StateStopped)
-						if session.OnTrackEnd != nil {
-							session.OnTrackEnd()
-						}
+						return
 					}
-					return
 				}
-				fmt.Printf("Error reading from ffmpeg: %v\n", err)
-				session.SetState(StateStopped)
-				return
 			}
 
-			if n < maxBytes {
+		default:
+			// Rather than blocking on a full buffer, wait a frame and go
+			// round again so controls are still picked up
+			if out.full() {
+				out.wait()
 				continue
 			}
 
-			// Convert bytes to int16
-			for i := 0; i < frameSize*channels; i++ {
-				pcmBuffer[i] = int16(binary.LittleEndian.Uint16(byteBuffer[i*2 : (i+1)*2]))
+			// Read the next packet as is, or the next frame of PCM
+			var opus []byte
+			if src.passthrough() {
+				opus, err = src.readPacket()
+			} else {
+				err = src.readFrame(pcmBuffer)
 			}
-
-			// Encode to Opus
-			opus, err := encoder.Encode(pcmBuffer, frameSize, maxBytes)
 			if err != nil {
-				fmt.Printf("Error encoding opus: %v\n", err)
+				finished := err == io.EOF || err == io.ErrUnexpectedEOF
+				early := finished && endedEarly(track, src.position)
+				if !finished {
+					fmt.Printf("Error reading audio: %v\n", err)
+				} else if early {
+					err = fmt.Errorf("stream ended early at %s of %s", src.position.Truncate(time.Second), track.Duration)
+				}
+
+				// The stream URL probably expired or the source lost its connection,
+				// so pick up where it left off with a fresh URL
+				if !finished || early {
+					if recoveries >= maxRecoveryAttempts {
+						played(true)
+						p.fail(session, track, err)
+						return
+					}
+					recoveries++
+
+					recovered, rerr := p.recover(session, src, track, recoveries)
+					src = recovered
+					switch {
+					case rerr == errRecoveryStopped:
+						played(true)
+						p.stop(session)
+						return
+					case rerr == errRecoverySkipped:
+						played(true)
+						p.advance(session, skipLoopMode(session.LoopMode()))
+						return
+					case rerr != nil:
+						played(true)
+						p.fail(session, track, rerr)
+						return
+					}
+					continue
+				}
+
+				// A preload that hasn't finished yet is still quicker than
+				// starting the next track from scratch
+				if next == nil && pending != nil {
+					result := <-pending
+					next, nextTrack, pending = result.src, result.track, nil
+				}
+
+				// Track finished, let what's buffered play out first
+				if next == nil {
+					out.drain()
+					// The track is over, so a skip sent while it played out
+					// was for this track and not the next
+					session.clearSkip()
+					played(false)
+					p.advance(session, session.LoopMode())
+					return
+				}
+
+				// Hand over to the preloaded source without stopping
+				played(false)
+				if !p.handover(session, nextTrack, next.position) {
+					return
+				}
+				src.close()
+				src, track = next, nextTrack
+				next, nextTrack = nil, nil
+				recoveries = 0
+				started, listened = time.Now(), 0
 				continue
 			}
 
-			// Send to Discord
-			if vc.Ready && vc.OpusSend != nil {
+			if window := preloadWindow(session); window > 0 && next == nil && pending == nil && track.Duration > 0 {
+				if track.Duration-src.position <= window {
+					pending = p.preload(session)
+				}
+			}
+			if pending != nil {
 				select {
-				case vc.OpusSend <- opus:
-				case <-time.After(time.Second):
-					// Timeout sending
+				case result := <-pending:
+					next, nextTrack, pending = result.src, result.track, nil
+				default:
 				}
 			}
+
+			if src.passthrough() {
+				// As soon as something needs to change the audio, or the packets
+				// don't fit Discord's 20ms frames, switch to decoding
+				if !canPassthrough(session, track) || opusPacketDuration(opus) != frameDuration {
+					dropNext()
+
+					if src, err = p.decode(session, src, track); err != nil {
+						played(true)
+						p.fail(session, track, err)
+						return
+					}
+					continue
+				}
+			} else {
+				// Fade into the next track over the last few seconds
+				if crossfade := session.Crossfade(); next != nil && !next.passthrough() && crossfade > 0 {
+					if remaining := track.Duration - src.position; remaining <= crossfade {
+						if err := next.readFrame(nextBuffer); err != nil {
+							// The next track is too short or broken, let the normal
+							// transition deal with it
+							dropNext()
+						} else {
+							crossfadeMix(pcmBuffer, nextBuffer, 1-float64(remaining)/float64(crossfade))
+						}
+					}
+				}
+
+				eq.apply(pcmBuffer, session.EQ())
+				gain.apply(pcmBuffer, session.Gain())
+
+				// Pick up encoder changes without restarting the track. libopus
+				// refuses to change the application once it has encoded a frame,
+				// so that takes a new encoder.
+				if current := session.Encoder(); current.Application != settings.Application {
+					if fresh, err := current.newEncoder(session.Bitrate()); err != nil {
+						fmt.Printf("Failed to create opus encoder: %v\n", err)
+					} else {
+						encoder, settings, bitrate = fresh, current, session.Bitrate()
+					}
+				} else if current != settings {
+					settings = current
+					settings.applyLoss(encoder)
+				}
+				if current := session.Bitrate(); current != bitrate {
+					bitrate = current
+					encoder.SetBitrate(bitrate)
+				}
+
+				// Encode to Opus
+				opus, err = encoder.Encode(pcmBuffer, frameSize, maxBytes)
+				if err != nil {
+					fmt.Printf("Error encoding opus: %v\n", err)
+					continue
+				}
+			}
+
+			out.send(opus)
+			if listened == 0 {
+				session.trackPlayed()
+			}
+			listened += frameDuration
 		}
 	}
 }
 
+// played reports a track that has finished or been cut short to the
+// session's play history
+func (p *Player) played(session *Session, track *Track, started time.Time, listened time.Duration, skipped bool) {
+	if session.OnTrackPlayed == nil {
+		return
+	}
+
+	go session.OnTrackPlayed(TrackPlay{
+		Track:     track,
+		StartedAt: started,
+		EndedAt:   time.Now(),
+		Listened:  listened,
+		Skipped:   skipped,
+	})
+}
+
+// canPassthrough reports whether track's Opus packets can be sent as they
+// are, which is only the case when nothing would change the audio
+func canPassthrough(session *Session, track *Track) bool {
+	return track.IsOpus() &&
+		session.Volume() == session.UnityVolume() &&
+		len(session.Filters()) == 0 &&
+		session.Speed() == 1 &&
+		session.Pitch() == 0 &&
+		session.EQ().IsFlat() &&
+		!session.Normalize() &&
+		session.Crossfade() == 0
+}
+
+// decode switches a passed through track over to the PCM pipeline at the
+// same position
+func (p *Player) decode(session *Session, src *trackSource, track *Track) (*trackSource, error) {
+	src.close()
+	return p.openDecoded(session, track, src.position)
+}
+
+// endedEarly reports whether a stream stopped well short of the track's
+// length, which means the connection dropped rather than the track finishing
+func endedEarly(track *Track, position time.Duration) bool {
+	return track.Duration > 0 && position < track.Duration-earlyEndTolerance
+}
+
+// Returned by recover when a stop or skip comes in while it waits
+var (
+	errRecoveryStopped = errors.New("stopped during recovery")
+	errRecoverySkipped = errors.New("skipped during recovery")
+)
+
+// recover re-resolves track's stream URL and reopens it from where the old
+// stream stopped
+func (p *Player) recover(session *Session, src *trackSource, track *Track, attempt int) (*trackSource, error) {
+	position := src.position
+	src.close()
+
+	fmt.Printf("Stream for %s dropped at %s, recovering (attempt %d/%d)\n",
+		track.Title, position.Truncate(time.Second), attempt, maxRecoveryAttempts)
+
+	// Back off a little in case the source is having a moment. Stop and
+	// skip still work meanwhile.
+	backoff := time.NewTimer(time.Duration(attempt) * time.Second)
+	defer backoff.Stop()
+	select {
+	case <-backoff.C:
+	case <-session.StopChan():
+		return nil, errRecoveryStopped
+	case <-session.SkipChan():
+		return nil, errRecoverySkipped
+	}
+
+	// yt-dlp can take a while too. An abandoned resolve finishes in the
+	// background.
+	track.ExpireStream()
+	resolved := make(chan error, 1)
+	go func() {
+		resolved <- p.resolve(session, track)
+	}()
+
+	select {
+	case err := <-resolved:
+		if err != nil {
+			return nil, fmt.Errorf("failed to re-resolve stream: %w", err)
+		}
+	case <-session.StopChan():
+		return nil, errRecoveryStopped
+	case <-session.SkipChan():
+		return nil, errRecoverySkipped
+	}
+
+	recovered, err := p.openSource(session, track, position)
+	if err != nil {
+		return nil, err
+	}
+
+	session.SetPosition(position)
+	return recovered, nil
+}
+
+// fail gives up on a track that couldn't be recovered and moves on
+func (p *Player) fail(session *Session, track *Track, err error) {
+	fmt.Printf("Giving up on %s: %v\n", track.Title, err)
+	if session.OnTrackError != nil {
+		session.OnTrackError(track, err)
+	}
+	p.skipFailed(session)
+}
+
+// skipFailed moves past a track that couldn't be played. Once every track
+// in the queue has failed in a row, looping the queue would only retry them
+// forever, so looping is turned off and playback runs out instead.
+func (p *Player) skipFailed(session *Session) {
+	mode := skipLoopMode(session.LoopMode())
+	if failures := session.trackFailed(); mode == LoopQueue && failures >= session.Queue().Len() {
+		fmt.Printf("All %d tracks in the queue failed, turning off queue loop\n", failures)
+		session.SetLoopMode(LoopOff)
+		mode = LoopOff
+	}
+	p.advance(session, mode)
+}
+
+// preloaded is a source opened ahead of time for the track after the
+// current one. Both are nil if it couldn't be opened.
+type preloaded struct {
+	src   *trackSource
+	track *Track
+}
+
+// preload opens the track that will play after the current one in the
+// background, since starting ffmpeg and reading an Opus stream's headers can
+// take longer than the buffered audio lasts. It only uses tracks the
+// prefetcher has already resolved, and returns nil if there's none yet.
+func (p *Player) preload(session *Session) <-chan preloaded {
+	track := session.Queue().PeekNext(session.LoopMode())
+	if track == nil || track.NeedsResolve() {
+		return nil
+	}
+
+	result := make(chan preloaded, 1)
+	go func() {
+		src, err := p.openSource(session, track, 0)
+		if err != nil {
+			fmt.Printf("Failed to preload %s: %v\n", track.Title, err)
+			result <- preloaded{}
+			return
+		}
+		result <- preloaded{src: src, track: track}
+	}()
+	return result
+}
+
+// abandonPreload closes whatever a preload opens once it's done
+func abandonPreload(pending <-chan preloaded) {
+	if pending == nil {
+		return
+	}
+	go func() {
+		if result := <-pending; result.src != nil {
+			result.src.close()
+		}
+	}()
+}
+
+// handover advances the queue to a track whose source is already playing.
+// If the queue changed since it was preloaded, the preloaded source is
+// abandoned and the queue's real next track is started the usual way.
+func (p *Player) handover(session *Session, track *Track, position time.Duration) bool {
+	next := session.Queue().Next(session.LoopMode())
+	if next != track {
+		if next != nil {
+			go p.playNext(session, next)
+		} else {
+			p.finish(session)
+		}
+		return false
+	}
+
+	session.SetState(StatePlaying)
+	// A crossfade means the track has already been playing for a while
+	session.SetPosition(position)
+
+	// Don't hold up the audio for the now playing message
+	if session.OnTrackChange != nil {
+		go session.OnTrackChange(track)
+	}
+
+	session.PrefetchUpcoming()
+	return true
+}
+
+// seek moves track's source to the requested position. A plain seek is
+// left to the source, while a reopen rebuilds it to pick up any change to
+// the session's filters.
+func (p *Player) seek(session *Session, src *trackSource, track *Track, req SeekRequest) (*trackSource, error) {
+	if !req.Reopen {
+		if err := src.seek(req.Position); err == nil {
+			return src, nil
+		}
+	}
+
+	src.close()
+	return p.openSource(session, track, req.Position)
+}
+
+// openSource opens track, passing it through untouched if possible
+func (p *Player) openSource(session *Session, track *Track, offset time.Duration) (*trackSource, error) {
+	if p.passthrough != nil && canPassthrough(session, track) {
+		packets, err := p.passthrough(track, offset)
+		if err == nil {
+			return &trackSource{
+				packets:  packets,
+				position: offset,
+				gain:     1,
+				rate:     1,
+			}, nil
+		}
+		fmt.Printf("Can't pass %s through, decoding instead: %v\n", track.Title, err)
+	}
+
+	return p.openDecoded(session, track, offset)
+}
+
+// openDecoded opens track as PCM with the session's current filters and
+// loudness normalization
+func (p *Player) openDecoded(session *Session, track *Track, offset time.Duration) (*trackSource, error) {
+	af := filterChain(session.Filters(), session.Speed(), session.Pitch())
+
+	// Measured tracks are normalized with a fixed gain in-process. Until a
+	// track has been measured ffmpeg's loudnorm does it on the fly.
+	gain := 1.0
+	if session.Normalize() {
+		if lufs := track.Loudness(); lufs != 0 {
+			gain = loudnessGain(lufs, track.TruePeak())
+		} else if af == "" {
+			af = loudnormFilter
+		} else {
+			af += "," + loudnormFilter
+		}
+	}
+
+	source, err := p.open(track, SourceOptions{Offset: offset, Filters: af})
+	if err != nil {
+		return nil, err
+	}
+
+	return &trackSource{
+		source:   source,
+		position: offset,
+		gain:     gain,
+		rate:     session.PlaybackRate(),
+	}, nil
+}
+
+func (p *Player) stop(session *Session) {
+	logStreamStats(session)
+	session.Queue().ClearAll()
+	session.SetState(StateStopped)
+	if session.OnTrackEnd != nil {
+		session.OnTrackEnd()
+	}
+}
+
+// advance moves on to the next track in the queue, or ends playback if
+// there is nothing left
+func (p *Player) advance(session *Session, mode LoopMode) {
+	next := session.Queue().Next(mode)
+	if next != nil {
+		go p.playNext(session, next)
+		return
+	}
+
+	p.finish(session)
+}
+
+// Autoplay refills allowed in a row without any of their tracks playing,
+// so a run of unplayable recommendations doesn't go on forever
+const maxAutoplayRefills = 2
+
+// finish handles the queue running out. In autoplay mode the queue is
+// topped up with related tracks instead of stopping.
+func (p *Player) finish(session *Session) {
+	if session.Autoplay() && session.OnQueueEnd != nil && session.takeAutoplayRefill() {
+		if tracks := session.OnQueueEnd(session.Queue().History()); len(tracks) > 0 {
+			session.Queue().Add(tracks...)
+			go p.playNext(session, session.Queue().Current())
+			return
+		}
+	}
+
+	logStreamStats(session)
+	session.SetState(StateStopped)
+	if session.OnTrackEnd != nil {
+		session.OnTrackEnd()
+	}
+}
+
+// logStreamStats reports any trouble getting audio out when playback ends
+func logStreamStats(session *Session) {
+	stats := session.StreamStats()
+	if stats.Underruns == 0 && stats.Dropped == 0 {
+		return
+	}
+	fmt.Printf("Stream stats for guild %s: %d underruns, %d dropped frames\n",
+		session.GuildID(), stats.Underruns, stats.Dropped)
+}
+
+// skipLoopMode is the loop mode to advance with on a manual skip. Skipping
+// a looped track moves past it rather than restarting it.
+func skipLoopMode(mode LoopMode) LoopMode {
+	if mode == LoopTrack {
+		return LoopOff
+	}
+	return mode
+}
+
+// resolve makes sure track is ready to stream. With a prefetcher this is
+// usually a no-op since the track was resolved while the previous one played.
+func (p *Player) resolve(session *Session, track *Track) error {
+	if prefetcher := session.Prefetcher(); prefetcher != nil {
+		return prefetcher.Resolve(track)
+	}
+
+	if track.StreamURL() == "" {
+		return fmt.Errorf("track has no stream URL")
+	}
+	return nil
+}
+
 func (p *Player) playNext(session *Session, track *Track) {
+	if err := p.resolve(session, track); err != nil {
+		fmt.Printf("Failed to resolve %s: %v\n", track.Title, err)
+		p.skipFailed(session)
+		return
+	}
+
 	session.SetState(StatePlaying)
 	session.SetStartedAt(time.Now())
 
@@ -219,7 +722,9 @@ func (p *Player) playNext(session *Session, track *Track) {
 		session.OnTrackChange(track)
 	}
 
-	p.stream(session, track)
+	session.PrefetchUpcoming()
+
+	p.stream(session, track, 0)
 }
 
 func (p *Player) PlayPrevious(session *Session) error {
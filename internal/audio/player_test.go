@@ -0,0 +1,153 @@
+package audio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const testTimeout = 2 * time.Second
+
+// newTestPlayer returns a player that plays an endless tone for every track
+func newTestPlayer() *Player {
+	return NewPlayerWithSource(func(track *Track, opts SourceOptions) (Source, error) {
+		return NewToneSource(440, 0.5, 0), nil
+	})
+}
+
+// newTestSession returns a session queued with tracks and a fake voice
+// connection whose OpusSend channel collects the frames the player sends
+func newTestSession(titles ...string) (*Session, chan []byte) {
+	session := NewSession("test", 100)
+
+	frames := make(chan []byte, 1000)
+	session.SetVoiceConnection(&discordgo.VoiceConnection{Ready: true, OpusSend: frames})
+
+	for _, title := range titles {
+		track := &Track{ID: title, Title: title}
+		track.SetStreamURL("tone://" + title)
+		session.Queue().Add(track)
+	}
+	return session, frames
+}
+
+// waitForFrame fails the test unless a frame arrives in time
+func waitForFrame(t *testing.T, frames <-chan []byte) {
+	t.Helper()
+	select {
+	case <-frames:
+	case <-time.After(testTimeout):
+		t.Fatal("no frames were sent")
+	}
+}
+
+func drain(frames <-chan []byte) {
+	for {
+		select {
+		case <-frames:
+		default:
+			return
+		}
+	}
+}
+
+func TestSkipAdvances(t *testing.T) {
+	session, frames := newTestSession("first", "second")
+
+	changed := make(chan *Track, 2)
+	session.OnTrackChange = func(track *Track) {
+		changed <- track
+	}
+
+	player := newTestPlayer()
+	if err := player.Play(session, nil); err != nil {
+		t.Fatalf("Play: %v", err)
+	}
+	defer session.Stop()
+
+	if track := <-changed; track.Title != "first" {
+		t.Fatalf("started with %q, want first", track.Title)
+	}
+	waitForFrame(t, frames)
+
+	session.Skip()
+
+	select {
+	case track := <-changed:
+		if track.Title != "second" {
+			t.Fatalf("skipped to %q, want second", track.Title)
+		}
+	case <-time.After(testTimeout):
+		t.Fatal("skip didn't change the track")
+	}
+	if current := session.Queue().Current(); current == nil || current.Title != "second" {
+		t.Fatalf("current track after skip is %v, want second", current)
+	}
+	waitForFrame(t, frames)
+}
+
+func TestPauseStopsFrames(t *testing.T) {
+	session, frames := newTestSession("only")
+
+	player := newTestPlayer()
+	if err := player.Play(session, nil); err != nil {
+		t.Fatalf("Play: %v", err)
+	}
+	defer session.Stop()
+
+	waitForFrame(t, frames)
+
+	session.Pause()
+	// Let a frame that was already on its way land
+	time.Sleep(2 * frameDuration)
+	drain(frames)
+
+	select {
+	case <-frames:
+		t.Fatal("frames were sent while paused")
+	case <-time.After(10 * frameDuration):
+	}
+
+	session.Resume()
+	waitForFrame(t, frames)
+}
+
+func TestStopEndsLoop(t *testing.T) {
+	session, frames := newTestSession("first", "second")
+
+	ended := make(chan struct{}, 1)
+	session.OnTrackEnd = func() {
+		ended <- struct{}{}
+	}
+
+	player := newTestPlayer()
+	if err := player.Play(session, nil); err != nil {
+		t.Fatalf("Play: %v", err)
+	}
+
+	waitForFrame(t, frames)
+
+	session.Stop()
+
+	select {
+	case <-ended:
+	case <-time.After(testTimeout):
+		t.Fatal("stop didn't end playback")
+	}
+	if !session.IsStopped() {
+		t.Fatal("session isn't stopped")
+	}
+	if !session.Queue().IsEmpty() {
+		t.Fatal("stop left tracks in the queue")
+	}
+
+	// Whatever was in flight lands within a tick, then nothing more
+	time.Sleep(2 * frameDuration)
+	drain(frames)
+	select {
+	case <-frames:
+		t.Fatal("frames were sent after stop")
+	case <-time.After(10 * frameDuration):
+	}
+}
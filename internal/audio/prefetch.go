@@ -0,0 +1,81 @@
+package audio
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ResolveFunc fills in a track's stream URL, duration and artwork
+type ResolveFunc func(track *Track) error
+
+// Prefetcher resolves upcoming tracks in the background so that track
+// transitions don't have to wait on yt-dlp
+type Prefetcher struct {
+	resolve ResolveFunc
+	depth   int
+
+	mu       sync.Mutex
+	inflight map[*Track]chan struct{}
+}
+
+func NewPrefetcher(resolve ResolveFunc, depth int) *Prefetcher {
+	return &Prefetcher{
+		resolve:  resolve,
+		depth:    depth,
+		inflight: make(map[*Track]chan struct{}),
+	}
+}
+
+func (p *Prefetcher) Depth() int {
+	return p.depth
+}
+
+// Resolve makes sure track has a usable stream URL. If the track is already
+// being resolved in the background it waits for that instead of running
+// yt-dlp a second time.
+func (p *Prefetcher) Resolve(track *Track) error {
+	for {
+		p.mu.Lock()
+		if wait, ok := p.inflight[track]; ok {
+			p.mu.Unlock()
+			<-wait
+			continue
+		}
+
+		if !track.NeedsResolve() {
+			p.mu.Unlock()
+			return nil
+		}
+
+		done := make(chan struct{})
+		p.inflight[track] = done
+		p.mu.Unlock()
+
+		err := p.resolve(track)
+
+		p.mu.Lock()
+		delete(p.inflight, track)
+		close(done)
+		p.mu.Unlock()
+
+		return err
+	}
+}
+
+// Prefetch resolves up to Depth of the given tracks in order, in the background
+func (p *Prefetcher) Prefetch(tracks []*Track) {
+	if len(tracks) > p.depth {
+		tracks = tracks[:p.depth]
+	}
+	if len(tracks) == 0 {
+		return
+	}
+
+	go func() {
+		for _, track := range tracks {
+			if err := p.Resolve(track); err != nil {
+				fmt.Printf("[prefetch] Failed to resolve %s: %v\n", track.Title, err)
+			}
+		}
+	}()
+}
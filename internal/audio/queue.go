@@ -5,6 +5,10 @@ import (
 	"sync"
 )
 
+// Only this many played tracks are remembered, so a queue left looping
+// doesn't grow its history forever
+const maxHistory = 100
+
 type Queue struct {
 	tracks   []*Track
 	history  []*Track
@@ -45,7 +49,9 @@ func (q *Queue) Current() *Track {
 	return q.tracks[0]
 }
 
-func (q *Queue) Next() *Track {
+// Next advances past the current track according to mode and returns the
+// track that should play next, or nil if the queue is exhausted
+func (q *Queue) Next(mode LoopMode) *Track {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
@@ -53,9 +59,21 @@ func (q *Queue) Next() *Track {
 		return nil
 	}
 
-	// Move current to history
 	current := q.tracks[0]
-	q.history = append(q.history, current)
+
+	switch mode {
+	case LoopTrack:
+		// Play the same track again
+		return current
+	case LoopQueue:
+		// Send the current track to the back so the queue cycles
+		q.addHistory(current)
+		q.tracks = append(q.tracks[1:], current)
+		return q.tracks[0]
+	}
+
+	// Move current to history
+	q.addHistory(current)
 
 	// Remove from queue
 	q.tracks = q.tracks[1:]
@@ -66,6 +84,33 @@ func (q *Queue) Next() *Track {
 	return q.tracks[0]
 }
 
+// addHistory records a played track, forgetting the oldest past maxHistory.
+// The caller holds the lock.
+func (q *Queue) addHistory(track *Track) {
+	q.history = append(q.history, track)
+	if len(q.history) > maxHistory {
+		q.history = q.history[len(q.history)-maxHistory:]
+	}
+}
+
+// PeekNext returns the track Next(mode) would move to, without changing the queue
+func (q *Queue) PeekNext(mode LoopMode) *Track {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	switch {
+	case len(q.tracks) == 0:
+		return nil
+	case mode == LoopTrack:
+		return q.tracks[0]
+	case len(q.tracks) > 1:
+		return q.tracks[1]
+	case mode == LoopQueue:
+		return q.tracks[0]
+	}
+	return nil
+}
+
 func (q *Queue) Previous() *Track {
 	q.mu.Lock()
 	defer q.mu.Unlock()
@@ -111,6 +156,27 @@ func (q *Queue) All() []*Track {
 	return result
 }
 
+// History returns previously played tracks, oldest first
+func (q *Queue) History() []*Track {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	result := make([]*Track, len(q.history))
+	copy(result, q.history)
+	return result
+}
+
+// SetHistory replaces the play history, oldest first, keeping at most
+// maxHistory tracks
+func (q *Queue) SetHistory(tracks []*Track) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(tracks) > maxHistory {
+		tracks = tracks[len(tracks)-maxHistory:]
+	}
+	q.history = append(make([]*Track, 0, len(tracks)), tracks...)
+}
+
 func (q *Queue) Upcoming() []*Track {
 	q.mu.RLock()
 	defer q.mu.RUnlock()
@@ -0,0 +1,21 @@
+package audio
+
+import "testing"
+
+func TestLoopQueueHistoryIsCapped(t *testing.T) {
+	q := NewQueue()
+	q.Add(&Track{Title: "first"}, &Track{Title: "second"}, &Track{Title: "third"})
+
+	for i := 0; i < maxHistory*3; i++ {
+		q.Next(LoopQueue)
+	}
+
+	history := q.History()
+	if len(history) != maxHistory {
+		t.Fatalf("history has %d tracks, want %d", len(history), maxHistory)
+	}
+	// The most recent track played is the one before the current one
+	if last := history[len(history)-1]; last.Title != "third" {
+		t.Fatalf("last played is %q, want third", last.Title)
+	}
+}
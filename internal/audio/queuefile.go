@@ -0,0 +1,406 @@
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QueueFormat is a file format a queue can be exported to and imported from
+type QueueFormat string
+
+const (
+	FormatM3U8 QueueFormat = "m3u8"
+	FormatXSPF QueueFormat = "xspf"
+	FormatJSON QueueFormat = "json"
+)
+
+// QueueFormats lists the supported formats in the order they are offered
+var QueueFormats = []QueueFormat{FormatM3U8, FormatXSPF, FormatJSON}
+
+func ParseQueueFormat(name string) (QueueFormat, bool) {
+	for _, f := range QueueFormats {
+		if string(f) == strings.ToLower(name) {
+			return f, true
+		}
+	}
+	return "", false
+}
+
+func (f QueueFormat) String() string {
+	switch f {
+	case FormatM3U8:
+		return "M3U8"
+	case FormatXSPF:
+		return "XSPF"
+	case FormatJSON:
+		return "JSON"
+	}
+	return string(f)
+}
+
+func (f QueueFormat) ContentType() string {
+	switch f {
+	case FormatM3U8:
+		return "audio/x-mpegurl"
+	case FormatXSPF:
+		return "application/xspf+xml"
+	}
+	return "application/json"
+}
+
+// M3U and XSPF have no notion of history, so history entries are played
+// first like they were and marked so importing skips them
+const (
+	m3uHistoryGroup = "History"
+	m3uQueueGroup   = "Queue"
+	xspfHistoryRel  = "https://github.com/dickeyy/meow/history"
+)
+
+// ExportQueue writes the queue, current track first, and the history,
+// oldest first, in the given format
+func ExportQueue(format QueueFormat, tracks, history []*Track) ([]byte, error) {
+	switch format {
+	case FormatM3U8:
+		return exportM3U(tracks, history), nil
+	case FormatXSPF:
+		return exportXSPF(tracks, history)
+	case FormatJSON:
+		return exportJSON(tracks, history)
+	}
+	return nil, fmt.Errorf("unknown queue format %q", format)
+}
+
+// ImportQueue reads a queue file, telling the format from the file name or
+// failing that its contents. History entries are left out. Entries may
+// have only a title or only a URL, and are for the caller to look up.
+func ImportQueue(name string, data []byte) ([]*Track, error) {
+	format, ok := ParseQueueFormat(strings.TrimPrefix(path.Ext(name), "."))
+	if !ok {
+		format = sniffQueueFormat(data)
+	}
+
+	var tracks []*Track
+	var err error
+	switch format {
+	case FormatXSPF:
+		tracks, err = importXSPF(data)
+	case FormatJSON:
+		tracks, err = importJSON(data)
+	default:
+		// Plain .m3u files and lists of URLs read the same way
+		tracks = importM3U(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s file: %w", format, err)
+	}
+	return tracks, nil
+}
+
+func sniffQueueFormat(data []byte) QueueFormat {
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("{")), bytes.HasPrefix(trimmed, []byte("[")):
+		return FormatJSON
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		return FormatXSPF
+	}
+	return FormatM3U8
+}
+
+// displayTitle is how a track is named in formats with a single title
+// field
+func displayTitle(t *Track) string {
+	if t.Artist == "" {
+		return t.Title
+	}
+	return t.Artist + " - " + t.Title
+}
+
+func exportM3U(tracks, history []*Track) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("#EXTM3U\n")
+
+	write := func(group string, list []*Track) {
+		for _, t := range list {
+			duration := -1
+			if t.Duration > 0 {
+				duration = int(t.Duration.Seconds())
+			}
+			// Line breaks would end the entry early
+			title := strings.Join(strings.Fields(displayTitle(t)), " ")
+
+			// Without a URL the title stands in for the location, and is
+			// searched for on import
+			location := t.URL
+			if location == "" {
+				location = title
+			}
+
+			fmt.Fprintf(&buf, "#EXTINF:%d,%s\n", duration, title)
+			fmt.Fprintf(&buf, "#EXTGRP:%s\n", group)
+			buf.WriteString(location + "\n")
+		}
+	}
+	write(m3uHistoryGroup, history)
+	write(m3uQueueGroup, tracks)
+
+	return buf.Bytes()
+}
+
+// importM3U reads extended or plain M3U. An entry whose location isn't a
+// URL is taken to be a title, since local file paths mean nothing here.
+func importM3U(data []byte) []*Track {
+	var tracks []*Track
+	var pending *Track
+	var group string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "#EXTINF:"):
+			pending = &Track{}
+			info := strings.TrimPrefix(line, "#EXTINF:")
+			if comma := strings.Index(info, ","); comma >= 0 {
+				// Attributes such as tvg-id may follow the duration
+				fields := strings.Fields(info[:comma])
+				if len(fields) > 0 {
+					if seconds, err := strconv.Atoi(fields[0]); err == nil && seconds > 0 {
+						pending.Duration = time.Duration(seconds) * time.Second
+					}
+				}
+				pending.Artist, pending.Title = splitDisplayTitle(strings.TrimSpace(info[comma+1:]))
+			}
+			continue
+		case strings.HasPrefix(line, "#EXTGRP:"):
+			group = strings.TrimSpace(strings.TrimPrefix(line, "#EXTGRP:"))
+			continue
+		case strings.HasPrefix(line, "#"):
+			continue
+		}
+
+		track := pending
+		if track == nil {
+			track = &Track{}
+		}
+		if isURL(line) {
+			track.URL = line
+		} else if track.Title == "" {
+			track.Artist, track.Title = splitDisplayTitle(strings.TrimSuffix(path.Base(line), path.Ext(line)))
+		}
+
+		if group != m3uHistoryGroup && (track.URL != "" || track.Title != "") {
+			tracks = append(tracks, track)
+		}
+		pending, group = nil, ""
+	}
+
+	return tracks
+}
+
+// splitDisplayTitle undoes displayTitle as far as it can
+func splitDisplayTitle(s string) (artist, title string) {
+	if artist, title, ok := strings.Cut(s, " - "); ok {
+		return strings.TrimSpace(artist), strings.TrimSpace(title)
+	}
+	return "", s
+}
+
+func isURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+type xspfPlaylist struct {
+	XMLName   xml.Name    `xml:"playlist"`
+	Version   string      `xml:"version,attr"`
+	Namespace string      `xml:"xmlns,attr"`
+	Title     string      `xml:"title,omitempty"`
+	Date      string      `xml:"date,omitempty"`
+	Tracks    []xspfTrack `xml:"trackList>track"`
+}
+
+type xspfTrack struct {
+	Location   string     `xml:"location,omitempty"`
+	Identifier string     `xml:"identifier,omitempty"`
+	Title      string     `xml:"title,omitempty"`
+	Creator    string     `xml:"creator,omitempty"`
+	Album      string     `xml:"album,omitempty"`
+	Duration   int64      `xml:"duration,omitempty"` // Milliseconds
+	Image      string     `xml:"image,omitempty"`
+	Meta       []xspfMeta `xml:"meta,omitempty"`
+}
+
+type xspfMeta struct {
+	Rel   string `xml:"rel,attr"`
+	Value string `xml:",chardata"`
+}
+
+func exportXSPF(tracks, history []*Track) ([]byte, error) {
+	playlist := xspfPlaylist{
+		Version:   "1",
+		Namespace: "http://xspf.org/ns/0/",
+		Title:     "Queue",
+		Date:      time.Now().Format(time.RFC3339),
+	}
+
+	add := func(list []*Track, isHistory bool) {
+		for _, t := range list {
+			track := xspfTrack{
+				Location: t.URL,
+				Title:    t.Title,
+				Creator:  t.Artist,
+				Album:    t.Album,
+				Duration: t.Duration.Milliseconds(),
+				Image:    t.Thumbnail,
+			}
+			if isHistory {
+				track.Meta = []xspfMeta{{Rel: xspfHistoryRel, Value: "true"}}
+			}
+			playlist.Tracks = append(playlist.Tracks, track)
+		}
+	}
+	add(history, true)
+	add(tracks, false)
+
+	out, err := xml.MarshalIndent(playlist, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
+
+func importXSPF(data []byte) ([]*Track, error) {
+	var playlist xspfPlaylist
+	if err := xml.Unmarshal(data, &playlist); err != nil {
+		return nil, err
+	}
+
+	var tracks []*Track
+	for _, t := range playlist.Tracks {
+		if xspfIsHistory(t) {
+			continue
+		}
+
+		track := &Track{
+			Title:     strings.TrimSpace(t.Title),
+			Artist:    strings.TrimSpace(t.Creator),
+			Album:     strings.TrimSpace(t.Album),
+			Duration:  time.Duration(t.Duration) * time.Millisecond,
+			Thumbnail: strings.TrimSpace(t.Image),
+		}
+		for _, location := range []string{t.Location, t.Identifier} {
+			if location = strings.TrimSpace(location); isURL(location) {
+				track.URL = location
+				break
+			}
+		}
+
+		if track.URL != "" || track.Title != "" {
+			tracks = append(tracks, track)
+		}
+	}
+	return tracks, nil
+}
+
+func xspfIsHistory(t xspfTrack) bool {
+	for _, meta := range t.Meta {
+		if meta.Rel == xspfHistoryRel && strings.TrimSpace(meta.Value) == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+type jsonQueue struct {
+	ExportedAt time.Time   `json:"exported_at"`
+	Tracks     []jsonTrack `json:"tracks"`
+	History    []jsonTrack `json:"history,omitempty"`
+}
+
+type jsonTrack struct {
+	ID         string  `json:"id,omitempty"`
+	Title      string  `json:"title"`
+	Artist     string  `json:"artist,omitempty"`
+	Album      string  `json:"album,omitempty"`
+	DurationMs int64   `json:"duration_ms,omitempty"`
+	URL        string  `json:"url,omitempty"`
+	Thumbnail  string  `json:"thumbnail,omitempty"`
+	Source     string  `json:"source,omitempty"`
+	Loudness   float64 `json:"loudness,omitempty"`
+	TruePeak   float64 `json:"true_peak,omitempty"`
+}
+
+func exportJSON(tracks, history []*Track) ([]byte, error) {
+	toJSON := func(list []*Track) []jsonTrack {
+		out := make([]jsonTrack, len(list))
+		for i, t := range list {
+			out[i] = jsonTrack{
+				ID:         t.ID,
+				Title:      t.Title,
+				Artist:     t.Artist,
+				Album:      t.Album,
+				DurationMs: t.Duration.Milliseconds(),
+				URL:        t.URL,
+				Thumbnail:  t.Thumbnail,
+				Source:     string(t.Source),
+				Loudness:   t.Loudness(),
+				TruePeak:   t.TruePeak(),
+			}
+		}
+		return out
+	}
+
+	out, err := json.MarshalIndent(jsonQueue{
+		ExportedAt: time.Now(),
+		Tracks:     toJSON(tracks),
+		History:    toJSON(history),
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// importJSON reads an exported queue, or a bare array of tracks
+func importJSON(data []byte) ([]*Track, error) {
+	var queue jsonQueue
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		if err := json.Unmarshal(data, &queue.Tracks); err != nil {
+			return nil, err
+		}
+	} else if err := json.Unmarshal(data, &queue); err != nil {
+		return nil, err
+	}
+
+	var tracks []*Track
+	for _, t := range queue.Tracks {
+		track := &Track{
+			ID:        t.ID,
+			Title:     strings.TrimSpace(t.Title),
+			Artist:    strings.TrimSpace(t.Artist),
+			Album:     t.Album,
+			Duration:  time.Duration(t.DurationMs) * time.Millisecond,
+			Thumbnail: t.Thumbnail,
+			Source:    TrackSource(t.Source),
+		}
+		track.SetLoudness(t.Loudness, t.TruePeak)
+		if isURL(t.URL) {
+			track.URL = t.URL
+		}
+
+		if track.URL != "" || track.Title != "" {
+			tracks = append(tracks, track)
+		}
+	}
+	return tracks, nil
+}
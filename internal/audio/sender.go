@@ -0,0 +1,185 @@
+package audio
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// How many encoded frames can be buffered ahead of the sender
+	jitterFrames = 25
+
+	// How many frames the sender waits for before starting, or after running
+	// dry, so one slow read doesn't turn into a stutter
+	primeFrames = 5
+)
+
+// StreamStats describe how smoothly audio is reaching Discord
+type StreamStats struct {
+	Underruns uint64 // Times the buffer ran dry while playing
+	Dropped   uint64 // Frames that couldn't be handed to Discord
+	Depth     int    // Frames currently buffered
+	Capacity  int
+}
+
+// streamStats is the live, lock-free side of StreamStats
+type streamStats struct {
+	underruns atomic.Uint64
+	dropped   atomic.Uint64
+	depth     atomic.Int64
+}
+
+func (s *streamStats) snapshot() StreamStats {
+	return StreamStats{
+		Underruns: s.underruns.Load(),
+		Dropped:   s.dropped.Load(),
+		Depth:     int(s.depth.Load()),
+		Capacity:  jitterFrames,
+	}
+}
+
+// frameSender sends encoded frames to Discord every 20ms from a bounded
+// buffer, so the stream loop can decode ahead and a slow read doesn't cause
+// a gap
+type frameSender struct {
+	frames   chan []byte
+	tick     chan struct{} // Signalled every 20ms while the sender runs
+	done     chan struct{}
+	stats    *streamStats
+	finished atomic.Bool
+	reprime  atomic.Bool
+	once     sync.Once
+}
+
+func newFrameSender(session *Session) *frameSender {
+	fs := &frameSender{
+		frames: make(chan []byte, jitterFrames),
+		tick:   make(chan struct{}, 1),
+		done:   make(chan struct{}),
+		stats:  &session.stats,
+	}
+	go fs.run(session)
+	return fs
+}
+
+// full reports whether the buffer has no room for another frame
+func (fs *frameSender) full() bool {
+	return len(fs.frames) == cap(fs.frames)
+}
+
+// wait blocks until the sender's next tick, which is when room may have
+// been made
+func (fs *frameSender) wait() {
+	<-fs.tick
+}
+
+// send queues a frame. The caller checks full first, since a full buffer
+// would block.
+func (fs *frameSender) send(frame []byte) {
+	fs.frames <- frame
+}
+
+// flush throws away everything buffered, e.g. after a seek
+func (fs *frameSender) flush() {
+	fs.reprime.Store(true)
+	for {
+		select {
+		case _, ok := <-fs.frames:
+			if !ok {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+// drain waits for everything buffered to be sent, then stops the sender
+func (fs *frameSender) drain() {
+	fs.finish()
+	<-fs.done
+}
+
+// stop throws away anything buffered and stops the sender
+func (fs *frameSender) stop() {
+	fs.flush()
+	fs.finish()
+}
+
+func (fs *frameSender) finish() {
+	fs.once.Do(func() {
+		fs.finished.Store(true)
+		close(fs.frames)
+	})
+}
+
+func (fs *frameSender) run(session *Session) {
+	defer close(fs.done)
+	defer fs.stats.depth.Store(0)
+
+	ticker := time.NewTicker(frameDuration)
+	defer ticker.Stop()
+
+	priming := true
+	for range ticker.C {
+		fs.stats.depth.Store(int64(len(fs.frames)))
+
+		select {
+		case fs.tick <- struct{}{}:
+		default:
+		}
+
+		// Nothing left to play once the session is stopped, even if the
+		// connection is down and the buffer can't be sent
+		if session.IsStopped() {
+			fs.flush()
+			if fs.finished.Load() {
+				return
+			}
+			continue
+		}
+
+		// Hold on to what's buffered so resuming picks up exactly where
+		// playback paused. The same goes for a dropped voice connection,
+		// which the player's voice watcher deals with.
+		vc := session.VoiceConnection()
+		if session.IsPaused() || vc == nil || !vc.Ready || vc.OpusSend == nil {
+			continue
+		}
+
+		if fs.reprime.Swap(false) {
+			priming = true
+		}
+		if priming {
+			if len(fs.frames) < primeFrames && !fs.finished.Load() {
+				continue
+			}
+			priming = false
+		}
+
+		select {
+		case frame, ok := <-fs.frames:
+			if !ok {
+				return
+			}
+			fs.deliver(vc, frame)
+		default:
+			fs.stats.underruns.Add(1)
+			priming = true
+		}
+	}
+}
+
+// deliver hands a frame to discordgo, which has its own small buffer. If
+// that is full the frame is dropped rather than holding up the ones behind
+// it.
+func (fs *frameSender) deliver(vc *discordgo.VoiceConnection, frame []byte) {
+	select {
+	case vc.OpusSend <- frame:
+	default:
+		fs.stats.dropped.Add(1)
+	}
+}
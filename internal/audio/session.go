@@ -1,6 +1,7 @@
 package audio
 
 import (
+	"strings"
 	"sync"
 	"time"
 
@@ -15,6 +16,39 @@ const (
 	StatePaused
 )
 
+type LoopMode int
+
+const (
+	LoopOff LoopMode = iota
+	LoopTrack
+	LoopQueue
+)
+
+func (m LoopMode) String() string {
+	switch m {
+	case LoopTrack:
+		return "Track"
+	case LoopQueue:
+		return "Queue"
+	default:
+		return "Off"
+	}
+}
+
+// Cycle returns the mode after m in the order off -> track -> queue -> off
+func (m LoopMode) Cycle() LoopMode {
+	return (m + 1) % 3
+}
+
+func ParseLoopMode(s string) (LoopMode, bool) {
+	for _, m := range []LoopMode{LoopOff, LoopTrack, LoopQueue} {
+		if strings.EqualFold(s, m.String()) {
+			return m, true
+		}
+	}
+	return LoopOff, false
+}
+
 type Session struct {
 	guildID         string
 	channelID       string
@@ -22,9 +56,29 @@ type Session struct {
 	queue           *Queue
 	state           PlayState
 	volume          int
+	unityVolume     int // The volume tracks play at their own level
+	loopMode        LoopMode
+	gapless         bool
+	crossfade       time.Duration
+	filters         []Filter
+	eq              EQBands
+	normalize       bool
+	speed           float64
+	pitch           float64
+	autoplay        bool
+	encoder         EncoderSettings
+	channelBitrate  int
+	reconnecting    bool
+	nowPlayingChan  string // Channel of the message showing the current track
+	nowPlayingID    string // Message showing the current track
+	skipVotes       skipVotes
+	failures        int // Tracks in a row that couldn't be played
+	autoplayRefills int // Autoplay refills since a track last played
 	startedAt       time.Time
 	pausedAt        time.Time
 	pausedDuration  time.Duration
+	pausedForVoice  bool // Paused by the voice watcher rather than a user
+	seekOffset      time.Duration
 	mu              sync.RWMutex
 
 	// Playback control
@@ -32,22 +86,40 @@ type Session struct {
 	pauseChan  chan struct{}
 	resumeChan chan struct{}
 	skipChan   chan struct{}
+	seekChan   chan SeekRequest
+
+	prefetcher    *Prefetcher
+	loudnessMeter *LoudnessMeter
+	stats         streamStats
 
 	// Callback when track changes
 	OnTrackChange func(track *Track)
 	OnTrackEnd    func()
+	OnTrackError  func(track *Track, err error)
+
+	// Called when a track finishes or is cut short. It runs in its own
+	// goroutine so it can't hold up the audio.
+	OnTrackPlayed func(play TrackPlay)
+
+	// Called in autoplay mode when the queue runs out, with the play
+	// history oldest first. Returns tracks to keep playing.
+	OnQueueEnd func(history []*Track) []*Track
 }
 
 func NewSession(guildID string, defaultVolume int) *Session {
 	return &Session{
-		guildID:    guildID,
-		queue:      NewQueue(),
-		state:      StateStopped,
-		volume:     defaultVolume,
-		stopChan:   make(chan struct{}, 1),
-		pauseChan:  make(chan struct{}, 1),
-		resumeChan: make(chan struct{}, 1),
-		skipChan:   make(chan struct{}, 1),
+		guildID:     guildID,
+		queue:       NewQueue(),
+		state:       StateStopped,
+		volume:      defaultVolume,
+		unityVolume: unityVolume(defaultVolume),
+		speed:       1,
+		encoder:     DefaultEncoderSettings(),
+		stopChan:    make(chan struct{}, 1),
+		pauseChan:   make(chan struct{}, 1),
+		resumeChan:  make(chan struct{}, 1),
+		skipChan:    make(chan struct{}, 1),
+		seekChan:    make(chan SeekRequest, 1),
 	}
 }
 
@@ -73,12 +145,147 @@ func (s *Session) SetChannelID(channelID string) {
 	s.channelID = channelID
 }
 
+// Reconnecting reports whether the player is in the middle of rejoining
+// the voice channel after losing its connection
+func (s *Session) Reconnecting() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.reconnecting
+}
+
+func (s *Session) SetReconnecting(reconnecting bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reconnecting = reconnecting
+}
+
 func (s *Session) ChannelID() string {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	return s.channelID
 }
 
+// NowPlayingMessage returns the channel and ID of the message showing the
+// current track, so it can be updated in place
+func (s *Session) NowPlayingMessage() (channelID, messageID string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.nowPlayingChan, s.nowPlayingID
+}
+
+func (s *Session) SetNowPlayingMessage(channelID, messageID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nowPlayingChan, s.nowPlayingID = channelID, messageID
+}
+
+// skipVotes are the votes to skip one track. They lapse by themselves once
+// another track is playing.
+type skipVotes struct {
+	track  *Track
+	voters map[string]bool
+	needed int
+}
+
+// VoteSkip records userID's vote to skip track, given who is listening now
+// and how many votes that makes needed. It returns the tally and whether
+// this was a new vote.
+func (s *Session) VoteSkip(track *Track, userID string, listeners []string, needed int) (votes int, added bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.skipVotes.track != track {
+		s.skipVotes = skipVotes{track: track, voters: make(map[string]bool)}
+	}
+	if !s.skipVotes.voters[userID] {
+		s.skipVotes.voters[userID] = true
+		added = true
+	}
+	return s.recountSkipVotes(listeners, needed), added
+}
+
+// RecountSkipVotes brings the vote to skip the current track in line with
+// who is listening now. It returns the new tally, or zeros if nobody has
+// voted.
+func (s *Session) RecountSkipVotes(listeners []string, needed int) (int, int) {
+	current := s.queue.Current()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if current == nil || s.skipVotes.track != current || len(s.skipVotes.voters) == 0 {
+		return 0, 0
+	}
+	return s.recountSkipVotes(listeners, needed), needed
+}
+
+// recountSkipVotes drops the votes of anyone who has stopped listening,
+// since they no longer count towards needed. The caller holds the lock.
+func (s *Session) recountSkipVotes(listeners []string, needed int) int {
+	listening := make(map[string]bool, len(listeners))
+	for _, userID := range listeners {
+		listening[userID] = true
+	}
+	for userID := range s.skipVotes.voters {
+		if !listening[userID] {
+			delete(s.skipVotes.voters, userID)
+		}
+	}
+	s.skipVotes.needed = needed
+	return len(s.skipVotes.voters)
+}
+
+func (s *Session) ClearSkipVotes() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.skipVotes = skipVotes{}
+}
+
+// SkipVotes returns the votes to skip the current track and how many are
+// needed, or zeros if nobody has voted
+func (s *Session) SkipVotes() (votes, needed int) {
+	current := s.queue.Current()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if current == nil || s.skipVotes.track != current {
+		return 0, 0
+	}
+	return len(s.skipVotes.voters), s.skipVotes.needed
+}
+
+func (s *Session) SetLoudnessMeter(m *LoudnessMeter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loudnessMeter = m
+}
+
+func (s *Session) LoudnessMeter() *LoudnessMeter {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.loudnessMeter
+}
+
+func (s *Session) SetPrefetcher(p *Prefetcher) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prefetcher = p
+}
+
+func (s *Session) Prefetcher() *Prefetcher {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.prefetcher
+}
+
+// PrefetchUpcoming starts resolving the next few queued tracks in the background
+func (s *Session) PrefetchUpcoming() {
+	p := s.Prefetcher()
+	if p == nil {
+		return
+	}
+	p.Prefetch(s.queue.Peek(p.Depth()))
+}
+
 func (s *Session) Queue() *Queue {
 	return s.queue
 }
@@ -113,6 +320,349 @@ func (s *Session) SetVolume(vol int) {
 	s.volume = vol
 }
 
+// UnityVolume is the volume that plays tracks at their own level, which is
+// the bot's default so Opus streams can pass through untouched
+func (s *Session) UnityVolume() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.unityVolume
+}
+
+// Gain is the volume as a multiplier of the track's own level
+func (s *Session) Gain() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return float64(s.volume) / float64(s.unityVolume)
+}
+
+func unityVolume(defaultVolume int) int {
+	// Muted by default would make every other volume infinitely loud
+	if defaultVolume <= 0 {
+		return 100
+	}
+	return defaultVolume
+}
+
+func (s *Session) LoopMode() LoopMode {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.loopMode
+}
+
+func (s *Session) SetLoopMode(mode LoopMode) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loopMode = mode
+}
+
+// trackFailed counts a track that couldn't be played and returns how many
+// have failed in a row
+func (s *Session) trackFailed() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures++
+	return s.failures
+}
+
+// trackPlayed resets the failure counts once a track gets audio out
+func (s *Session) trackPlayed() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = 0
+	s.autoplayRefills = 0
+}
+
+// takeAutoplayRefill reports whether autoplay may top up the queue again,
+// counting the refill if so
+func (s *Session) takeAutoplayRefill() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.autoplayRefills >= maxAutoplayRefills {
+		return false
+	}
+	s.autoplayRefills++
+	return true
+}
+
+func (s *Session) Gapless() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.gapless
+}
+
+func (s *Session) SetGapless(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gapless = enabled
+}
+
+func (s *Session) Crossfade() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.crossfade
+}
+
+func (s *Session) SetCrossfade(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if d < 0 {
+		d = 0
+	}
+	if d > MaxCrossfade {
+		d = MaxCrossfade
+	}
+	s.crossfade = d
+}
+
+func (s *Session) Filters() []Filter {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]Filter, len(s.filters))
+	copy(result, s.filters)
+	return result
+}
+
+func (s *Session) HasFilter(f Filter) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, active := range s.filters {
+		if active == f {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Session) EnableFilter(f Filter) {
+	if s.HasFilter(f) {
+		return
+	}
+
+	// Some presets change the playback rate, so take the position first
+	position := s.Elapsed()
+
+	s.mu.Lock()
+	s.filters = append(s.filters, f)
+	s.mu.Unlock()
+
+	s.restart(position)
+}
+
+func (s *Session) DisableFilter(f Filter) {
+	position := s.Elapsed()
+
+	s.mu.Lock()
+	filters := make([]Filter, 0, len(s.filters))
+	for _, active := range s.filters {
+		if active != f {
+			filters = append(filters, active)
+		}
+	}
+	changed := len(filters) != len(s.filters)
+	s.filters = filters
+	s.mu.Unlock()
+
+	if changed {
+		s.restart(position)
+	}
+}
+
+func (s *Session) ClearFilters() {
+	position := s.Elapsed()
+
+	s.mu.Lock()
+	changed := len(s.filters) > 0
+	s.filters = nil
+	s.mu.Unlock()
+
+	if changed {
+		s.restart(position)
+	}
+}
+
+func (s *Session) Speed() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.speed
+}
+
+func (s *Session) SetSpeed(speed float64) {
+	if speed < MinSpeed {
+		speed = MinSpeed
+	}
+	if speed > MaxSpeed {
+		speed = MaxSpeed
+	}
+
+	position := s.Elapsed()
+
+	s.mu.Lock()
+	changed := s.speed != speed
+	s.speed = speed
+	s.mu.Unlock()
+
+	if changed {
+		s.restart(position)
+	}
+}
+
+// Pitch is the pitch shift in semitones, applied without changing tempo
+func (s *Session) Pitch() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.pitch
+}
+
+func (s *Session) SetPitch(semitones float64) {
+	if semitones < -MaxPitch {
+		semitones = -MaxPitch
+	}
+	if semitones > MaxPitch {
+		semitones = MaxPitch
+	}
+
+	position := s.Elapsed()
+
+	s.mu.Lock()
+	changed := s.pitch != semitones
+	s.pitch = semitones
+	s.mu.Unlock()
+
+	if changed {
+		s.restart(position)
+	}
+}
+
+// PlaybackRate is how fast the track plays relative to normal, combining
+// the speed setting with rate-changing filter presets
+func (s *Session) PlaybackRate() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.playbackRate()
+}
+
+func (s *Session) playbackRate() float64 {
+	return s.speed * filterRate(s.filters)
+}
+
+// restart reopens the current source at position so filter changes are
+// heard straight away rather than on the next track
+func (s *Session) restart(position time.Duration) {
+	if s.IsStopped() {
+		return
+	}
+
+	// Live streams can't be seeked, so they just pick up from the live edge
+	if track := s.queue.Current(); track != nil && track.Duration == 0 {
+		position = 0
+	}
+	s.seek(SeekRequest{Position: position, Reopen: true})
+}
+
+func (s *Session) EQ() EQBands {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.eq
+}
+
+func (s *Session) SetEQ(bands EQBands) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range bands {
+		bands[i] = clampEQGain(bands[i])
+	}
+	s.eq = bands
+}
+
+func (s *Session) SetEQBand(band int, gain float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if band < 0 || band >= EQBandCount {
+		return
+	}
+	s.eq[band] = clampEQGain(gain)
+}
+
+func clampEQGain(gain float64) float64 {
+	if gain > MaxEQGain {
+		return MaxEQGain
+	}
+	if gain < -MaxEQGain {
+		return -MaxEQGain
+	}
+	return gain
+}
+
+func (s *Session) Normalize() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.normalize
+}
+
+func (s *Session) SetNormalize(enabled bool) {
+	position := s.Elapsed()
+
+	s.mu.Lock()
+	changed := s.normalize != enabled
+	s.normalize = enabled
+	s.mu.Unlock()
+
+	if changed {
+		s.restart(position)
+	}
+}
+
+func (s *Session) Autoplay() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.autoplay
+}
+
+func (s *Session) SetAutoplay(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.autoplay = enabled
+}
+
+func (s *Session) Encoder() EncoderSettings {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.encoder
+}
+
+// SetEncoder changes the encoder settings, taking effect on the next frame
+func (s *Session) SetEncoder(settings EncoderSettings) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.encoder = settings
+}
+
+// ChannelBitrate is the voice channel's bitrate limit, or 0 if unknown
+func (s *Session) ChannelBitrate() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.channelBitrate
+}
+
+func (s *Session) SetChannelBitrate(bitrate int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.channelBitrate = bitrate
+}
+
+// Bitrate is the bitrate audio is currently encoded at
+func (s *Session) Bitrate() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.encoder.bitrate(s.channelBitrate)
+}
+
+// StreamStats reports how smoothly audio has been reaching Discord over
+// the whole session
+func (s *Session) StreamStats() StreamStats {
+	return s.stats.snapshot()
+}
+
 func (s *Session) IsPlaying() bool {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -142,6 +692,7 @@ func (s *Session) SetStartedAt(t time.Time) {
 	defer s.mu.Unlock()
 	s.startedAt = t
 	s.pausedDuration = 0
+	s.seekOffset = 0
 }
 
 func (s *Session) Elapsed() time.Duration {
@@ -152,21 +703,51 @@ func (s *Session) Elapsed() time.Duration {
 		return 0
 	}
 
+	played := time.Since(s.startedAt) - s.pausedDuration
 	if s.state == StatePaused && !s.pausedAt.IsZero() {
-		return s.pausedAt.Sub(s.startedAt) - s.pausedDuration
+		played = s.pausedAt.Sub(s.startedAt) - s.pausedDuration
 	}
 
-	return time.Since(s.startedAt) - s.pausedDuration
+	// Elapsed is a position in the track, so it moves faster than the
+	// clock when the track is sped up
+	return s.seekOffset + time.Duration(float64(played)*s.playbackRate())
+}
+
+// SetPosition records that the current track is already at position, e.g.
+// after crossfading into it, without restarting the player
+func (s *Session) SetPosition(position time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.startedAt = time.Now()
+	s.pausedAt = time.Time{}
+	s.pausedDuration = 0
+	s.seekOffset = position
 }
 
 func (s *Session) Pause() {
+	s.pause(false)
+}
+
+// pauseForVoice pauses while the voice connection is down. Unlike a pause
+// from a user, it is undone once the connection is back.
+func (s *Session) pauseForVoice() {
+	s.pause(true)
+}
+
+func (s *Session) pause(forVoice bool) {
 	s.mu.Lock()
 	if s.state != StatePlaying {
+		// A user pausing while the connection is down wants it to stay
+		// paused once it's back
+		if !forVoice {
+			s.pausedForVoice = false
+		}
 		s.mu.Unlock()
 		return
 	}
 	s.state = StatePaused
 	s.pausedAt = time.Now()
+	s.pausedForVoice = forVoice
 	s.mu.Unlock()
 
 	select {
@@ -184,6 +765,7 @@ func (s *Session) Resume() {
 	s.pausedDuration += time.Since(s.pausedAt)
 	s.state = StatePlaying
 	s.pausedAt = time.Time{}
+	s.pausedForVoice = false
 	s.mu.Unlock()
 
 	select {
@@ -192,6 +774,14 @@ func (s *Session) Resume() {
 	}
 }
 
+// isPausedForVoice reports whether playback is paused only because the
+// voice connection went down
+func (s *Session) isPausedForVoice() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.state == StatePaused && s.pausedForVoice
+}
+
 func (s *Session) Skip() {
 	select {
 	case s.skipChan <- struct{}{}:
@@ -199,12 +789,69 @@ func (s *Session) Skip() {
 	}
 }
 
+// clearSkip drops a skip that arrived too late for the track it was meant
+// for, so it isn't applied to the next one
+func (s *Session) clearSkip() {
+	select {
+	case <-s.skipChan:
+	default:
+	}
+}
+
+// SeekRequest asks the player to move to a new position in the current
+// track. Reopen means the source's pipeline has to be rebuilt as well, e.g.
+// because the filters changed.
+type SeekRequest struct {
+	Position time.Duration
+	Reopen   bool
+}
+
+// Seek moves the current track to position. Elapsed reflects the new
+// position immediately, while the player seeks the source in the background.
+func (s *Session) Seek(position time.Duration) {
+	s.seek(SeekRequest{Position: position})
+}
+
+func (s *Session) seek(req SeekRequest) {
+	if req.Position < 0 {
+		req.Position = 0
+	}
+	position := req.Position
+
+	s.mu.Lock()
+	if s.state == StateStopped {
+		s.mu.Unlock()
+		return
+	}
+	now := time.Now()
+	s.startedAt = now
+	s.pausedDuration = 0
+	s.seekOffset = position
+	if s.state == StatePaused {
+		s.pausedAt = now
+	}
+	s.mu.Unlock()
+
+	// Only the most recent position matters, but a pending reopen still has
+	// to happen
+	select {
+	case pending := <-s.seekChan:
+		req.Reopen = req.Reopen || pending.Reopen
+	default:
+	}
+	select {
+	case s.seekChan <- req:
+	default:
+	}
+}
+
 func (s *Session) Stop() {
 	s.mu.Lock()
 	s.state = StateStopped
 	s.startedAt = time.Time{}
 	s.pausedAt = time.Time{}
 	s.pausedDuration = 0
+	s.seekOffset = 0
 	s.mu.Unlock()
 
 	select {
@@ -229,3 +876,7 @@ func (s *Session) SkipChan() <-chan struct{} {
 	return s.skipChan
 }
 
+func (s *Session) SeekChan() <-chan SeekRequest {
+	return s.seekChan
+}
+
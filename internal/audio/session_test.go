@@ -0,0 +1,50 @@
+package audio
+
+import "testing"
+
+func TestVoicePauseResumesOnlyItself(t *testing.T) {
+	session := NewSession("test", 100)
+	session.SetState(StatePlaying)
+
+	session.pauseForVoice()
+	if !session.isPausedForVoice() {
+		t.Fatal("pause for the outage isn't marked as the voice watcher's")
+	}
+
+	// A user pausing during the outage takes the pause over
+	session.Pause()
+	if session.isPausedForVoice() {
+		t.Fatal("user pause during the outage would be resumed with the connection")
+	}
+	if !session.IsPaused() {
+		t.Fatal("session isn't paused")
+	}
+
+	session.Resume()
+	session.Pause()
+	if session.isPausedForVoice() {
+		t.Fatal("user pause is marked as the voice watcher's")
+	}
+}
+
+func TestSkipVotesFollowListeners(t *testing.T) {
+	session := NewSession("test", 100)
+	track := &Track{Title: "only"}
+	session.Queue().Add(track)
+
+	everyone := []string{"a", "b", "c", "d"}
+	session.VoteSkip(track, "a", everyone, 3)
+	if votes, _ := session.VoteSkip(track, "b", everyone, 3); votes != 2 {
+		t.Fatalf("got %d votes, want 2", votes)
+	}
+
+	// b leaves and d deafens, so only a's vote still counts, and of the two
+	// left listening one vote is now enough
+	votes, needed := session.RecountSkipVotes([]string{"a", "c"}, 1)
+	if votes != 1 || needed != 1 {
+		t.Fatalf("recount is %d of %d, want 1 of 1", votes, needed)
+	}
+	if votes, needed := session.SkipVotes(); votes != 1 || needed != 1 {
+		t.Fatalf("tally is %d of %d, want 1 of 1", votes, needed)
+	}
+}
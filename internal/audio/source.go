@@ -0,0 +1,213 @@
+package audio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// Source produces 48kHz stereo PCM for the player, 20ms at a time
+type Source interface {
+	// ReadFrame fills pcm with the next frame. It returns io.EOF or
+	// io.ErrUnexpectedEOF once the source has nothing left.
+	ReadFrame(pcm []int16) error
+
+	// Seek moves the source so the next frame starts at position
+	Seek(position time.Duration) error
+
+	Close() error
+}
+
+// SourceOptions describe how a track should be opened
+type SourceOptions struct {
+	Offset time.Duration // Where in the track to start
+
+	// ffmpeg -af filter chain. Sources that can't filter ignore it.
+	Filters string
+}
+
+// SourceOpener opens a Source for a resolved track
+type SourceOpener func(track *Track, opts SourceOptions) (Source, error)
+
+// OpenFFmpeg is the default SourceOpener. It decodes the track's stream URL,
+// which may also be a local file path, with ffmpeg.
+func OpenFFmpeg(track *Track, opts SourceOptions) (Source, error) {
+	if track.StreamURL() == "" {
+		return nil, fmt.Errorf("track has no stream URL")
+	}
+	return NewFFmpegSource(track.StreamURL(), opts.Offset, opts.Filters)
+}
+
+// PacketSource hands over a stream's Opus packets as they are. It is used
+// instead of a Source when nothing needs to touch the audio, which saves
+// decoding and re-encoding every frame.
+type PacketSource interface {
+	// ReadPacket returns the next Opus packet. It returns io.EOF or
+	// io.ErrUnexpectedEOF once the source has nothing left.
+	ReadPacket() ([]byte, error)
+
+	Seek(position time.Duration) error
+
+	Close() error
+}
+
+// PacketOpener opens a PacketSource for a resolved track, failing if the
+// track's stream can't be passed through
+type PacketOpener func(track *Track, offset time.Duration) (PacketSource, error)
+
+// PCMSource reads raw 48kHz stereo s16le PCM, e.g. from a file or a
+// bytes.Reader. Seeking works when the reader is also an io.Seeker.
+type PCMSource struct {
+	r   io.Reader
+	buf []byte
+}
+
+func NewPCMSource(r io.Reader) *PCMSource {
+	return &PCMSource{r: r, buf: make([]byte, maxBytes)}
+}
+
+func (s *PCMSource) ReadFrame(pcm []int16) error {
+	if _, err := io.ReadFull(s.r, s.buf); err != nil {
+		return err
+	}
+	decodePCM(pcm, s.buf)
+	return nil
+}
+
+func (s *PCMSource) Seek(position time.Duration) error {
+	seeker, ok := s.r.(io.Seeker)
+	if !ok {
+		return fmt.Errorf("pcm source is not seekable")
+	}
+
+	// Keep to whole samples so the channels don't swap
+	offset := int64(position/frameDuration) * maxBytes
+	_, err := seeker.Seek(offset, io.SeekStart)
+	return err
+}
+
+// Close closes the underlying reader if it is an io.Closer
+func (s *PCMSource) Close() error {
+	if closer, ok := s.r.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// ToneSource generates a sine wave, which is handy for checking the audio
+// path without a real track
+type ToneSource struct {
+	frequency float64
+	amplitude float64
+	duration  time.Duration // Zero plays forever
+	position  time.Duration
+}
+
+// NewToneSource returns a tone at frequency Hz and amplitude between 0 and 1
+func NewToneSource(frequency, amplitude float64, duration time.Duration) *ToneSource {
+	return &ToneSource{
+		frequency: frequency,
+		amplitude: math.Max(0, math.Min(1, amplitude)),
+		duration:  duration,
+	}
+}
+
+func (s *ToneSource) ReadFrame(pcm []int16) error {
+	if s.duration > 0 && s.position >= s.duration {
+		return io.EOF
+	}
+
+	start := int64(s.position / (time.Second / sampleRate))
+	for i := 0; i < frameSize; i++ {
+		t := float64(start+int64(i)) / sampleRate
+		sample := clampSample(s.amplitude * math.MaxInt16 * math.Sin(2*math.Pi*s.frequency*t))
+		pcm[i*channels] = sample
+		pcm[i*channels+1] = sample
+	}
+
+	s.position += frameDuration
+	return nil
+}
+
+func (s *ToneSource) Seek(position time.Duration) error {
+	s.position = position
+	return nil
+}
+
+func (s *ToneSource) Close() error {
+	return nil
+}
+
+// trackSource layers the player's per-track state over a Source, or over a
+// PacketSource when the track is passed through
+type trackSource struct {
+	source   Source
+	packets  PacketSource
+	position time.Duration // Track position of the next frame
+	gain     float64       // Fixed per-track gain, e.g. from loudness normalization
+	rate     float64       // Playback rate, so position tracks the source
+}
+
+func (t *trackSource) readFrame(pcm []int16) error {
+	if err := t.source.ReadFrame(pcm); err != nil {
+		return err
+	}
+
+	if t.gain != 1 {
+		for i := range pcm {
+			pcm[i] = clampSample(float64(pcm[i]) * t.gain)
+		}
+	}
+
+	t.position += time.Duration(float64(frameDuration) * t.rate)
+	return nil
+}
+
+// readPacket returns the next Opus packet of a passed through track
+func (t *trackSource) readPacket() ([]byte, error) {
+	packet, err := t.packets.ReadPacket()
+	if err != nil {
+		return nil, err
+	}
+
+	t.position += opusPacketDuration(packet)
+	return packet, nil
+}
+
+func (t *trackSource) passthrough() bool {
+	return t.packets != nil
+}
+
+func (t *trackSource) seek(position time.Duration) error {
+	var err error
+	if t.passthrough() {
+		err = t.packets.Seek(position)
+	} else {
+		err = t.source.Seek(position)
+	}
+	if err != nil {
+		return err
+	}
+	t.position = position
+	return nil
+}
+
+func (t *trackSource) close() {
+	if t == nil {
+		return
+	}
+	if t.passthrough() {
+		t.packets.Close()
+	} else {
+		t.source.Close()
+	}
+}
+
+// decodePCM converts a frame of little-endian s16 bytes into samples
+func decodePCM(pcm []int16, buf []byte) {
+	for i := 0; i < frameSize*channels; i++ {
+		pcm[i] = int16(binary.LittleEndian.Uint16(buf[i*2 : (i+1)*2]))
+	}
+}
@@ -1,6 +1,11 @@
 package audio
 
-import "time"
+import (
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
 
 type TrackSource string
 
@@ -11,18 +16,112 @@ const (
 	SourceUnknown TrackSource = "unknown"
 )
 
+// Stream URLs are re-resolved once they are this close to expiring
+const streamRefreshMargin = 10 * time.Minute
+
 type Track struct {
 	ID          string
 	Title       string
 	Artist      string
 	Album       string
 	Duration    time.Duration
-	URL         string        // Original URL
-	StreamURL   string        // Direct stream URL (from yt-dlp)
-	Thumbnail   string        // Album art / thumbnail URL
+	URL         string // Original URL
+	Thumbnail   string // Album art / thumbnail URL
 	Source      TrackSource
-	RequestedBy string        // User ID who requested the track
-	PlaylistID  string        // If part of a playlist
+	RequestedBy string // User ID who requested the track
+	PlaylistID  string // If part of a playlist
+
+	// The stream and loudness are filled in by the prefetcher and loudness
+	// meter while the player may be reading them
+	mu              sync.Mutex
+	streamURL       string    // Direct stream URL (from yt-dlp)
+	streamExpiresAt time.Time // When streamURL stops working, zero if unknown
+	loudness        float64   // Integrated loudness in LUFS, 0 until measured
+	truePeak        float64   // True peak in dBTP, measured along with loudness
+}
+
+// StreamURL is the direct stream URL, empty until the track is resolved
+func (t *Track) StreamURL() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.streamURL
+}
+
+// SetStreamURL stores a freshly resolved stream URL and its expiry
+func (t *Track) SetStreamURL(streamURL string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.streamURL = streamURL
+	t.streamExpiresAt = streamExpiry(streamURL)
+}
+
+// ExpireStream marks the stream URL as unusable so it is resolved again
+func (t *Track) ExpireStream() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.streamExpiresAt = time.Now()
+}
+
+// NeedsResolve reports whether the track is missing a stream URL or the one
+// it has is about to expire
+func (t *Track) NeedsResolve() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.streamURL == "" {
+		return true
+	}
+	return !t.streamExpiresAt.IsZero() && time.Until(t.streamExpiresAt) < streamRefreshMargin
+}
+
+// Loudness is the track's integrated loudness in LUFS, 0 until measured
+func (t *Track) Loudness() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.loudness
+}
+
+// TruePeak is the track's true peak in dBTP. Measurements from before peaks
+// were recorded have 0, which keeps normalization from boosting them.
+func (t *Track) TruePeak() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.truePeak
+}
+
+func (t *Track) SetLoudness(lufs, truePeak float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.loudness = lufs
+	t.truePeak = truePeak
+}
+
+// IsOpus reports whether the stream looks like Opus in WebM or Ogg, going
+// by the mime type YouTube embeds in its stream URLs
+func (t *Track) IsOpus() bool {
+	u, err := url.Parse(t.StreamURL())
+	if err != nil {
+		return false
+	}
+
+	switch u.Query().Get("mime") {
+	case "audio/webm", "audio/ogg":
+		return true
+	}
+	return false
+}
+
+// streamExpiry reads the expire timestamp YouTube embeds in its stream URLs
+func streamExpiry(streamURL string) time.Time {
+	u, err := url.Parse(streamURL)
+	if err != nil {
+		return time.Time{}
+	}
+
+	secs, err := strconv.ParseInt(u.Query().Get("expire"), 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(secs, 0)
 }
 
 func (t *Track) FormatDuration() string {
@@ -68,3 +167,12 @@ func itoa(n int) string {
 	return digits
 }
 
+// TrackPlay records one play of a track, from when it started to when it
+// finished or was skipped
+type TrackPlay struct {
+	Track     *Track
+	StartedAt time.Time
+	EndedAt   time.Time
+	Listened  time.Duration // Audio actually sent, leaving out pauses
+	Skipped   bool
+}
@@ -0,0 +1,46 @@
+package audio
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// How long before the end of a track the next source is opened in
+	// gapless mode, leaving ffmpeg time to connect and buffer
+	preloadLead = 5 * time.Second
+
+	MaxCrossfade = 12 * time.Second
+)
+
+// preloadWindow is how close to the end of the current track the next
+// track's source should be open, or 0 if transitions aren't preloaded
+func preloadWindow(session *Session) time.Duration {
+	crossfade := session.Crossfade()
+	if crossfade > 0 {
+		return crossfade + preloadLead
+	}
+	if session.Gapless() {
+		return preloadLead
+	}
+	return 0
+}
+
+// crossfadeMix blends the incoming frame into the outgoing one in place.
+// progress runs from 0 (all outgoing) to 1 (all incoming) and uses an
+// equal-power curve so the overall loudness doesn't dip mid-fade.
+func crossfadeMix(out, in []int16, progress float64) {
+	if progress < 0 {
+		progress = 0
+	}
+	if progress > 1 {
+		progress = 1
+	}
+
+	outGain := math.Cos(progress * math.Pi / 2)
+	inGain := math.Sin(progress * math.Pi / 2)
+
+	for i := range out {
+		out[i] = clampSample(float64(out[i])*outGain + float64(in[i])*inGain)
+	}
+}
@@ -0,0 +1,118 @@
+package audio
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	voiceCheckInterval = 500 * time.Millisecond
+
+	// discordgo reconnects by itself when the voice server moves, so it is
+	// given this long to recover before rejoining ourselves
+	rejoinAfter       = 10 * time.Second
+	maxRejoinAttempts = 3
+)
+
+// watchVoice keeps an eye on the voice connection while a track streams.
+// Playback is paused while the connection is down and picks up from the
+// same position once it is back, rejoining the channel if need be.
+func (p *Player) watchVoice(session *Session, done <-chan struct{}) {
+	ticker := time.NewTicker(voiceCheckInterval)
+	defer ticker.Stop()
+
+	var channelID string
+	var lost time.Time
+	attempts := 0
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		if session.IsStopped() {
+			return
+		}
+
+		vc := session.VoiceConnection()
+		if vc != nil && vc.Ready {
+			// Follow the bot if it is moved to another channel
+			channelID = vc.ChannelID
+
+			if !lost.IsZero() {
+				fmt.Printf("Voice connection for guild %s is back after %s\n",
+					session.GuildID(), time.Since(lost).Truncate(time.Second))
+			}
+			// Only undo the pause made for the outage, not one a user made
+			// while it lasted
+			if session.isPausedForVoice() {
+				session.Resume()
+			}
+			lost, attempts = time.Time{}, 0
+			continue
+		}
+
+		if vc != nil && channelID == "" {
+			channelID = vc.ChannelID
+		}
+
+		// Pause straight away so the position doesn't run on while nobody
+		// can hear it. The buffered audio is held until the connection is
+		// back, so nothing is lost.
+		if lost.IsZero() {
+			fmt.Printf("Voice connection for guild %s lost, pausing\n", session.GuildID())
+			lost = time.Now()
+			session.pauseForVoice()
+			continue
+		}
+
+		if time.Since(lost) < rejoinAfter*time.Duration(attempts+1) {
+			continue
+		}
+
+		if attempts >= maxRejoinAttempts || channelID == "" {
+			fmt.Printf("Giving up on the voice connection for guild %s\n", session.GuildID())
+			session.Stop()
+			return
+		}
+		attempts++
+
+		fmt.Printf("Rejoining voice channel %s (attempt %d/%d)\n", channelID, attempts, maxRejoinAttempts)
+		if err := p.rejoin(session, vc, channelID); err != nil {
+			fmt.Printf("Failed to rejoin voice channel: %v\n", err)
+		}
+	}
+}
+
+// rejoin drops what's left of the old voice connection and joins
+// channelID again with a fresh one
+func (p *Player) rejoin(session *Session, old *discordgo.VoiceConnection, channelID string) error {
+	p.mu.Lock()
+	discord := p.discord
+	p.mu.Unlock()
+	if discord == nil {
+		return fmt.Errorf("no discord session to rejoin with")
+	}
+
+	// Leaving makes Discord send a voice state update for the bot, which
+	// mustn't be mistaken for it being kicked
+	session.SetReconnecting(true)
+	defer session.SetReconnecting(false)
+
+	if old != nil {
+		old.Disconnect()
+	}
+
+	vc, err := discord.ChannelVoiceJoin(session.GuildID(), channelID, false, true)
+	if err != nil {
+		return err
+	}
+
+	session.SetVoiceConnection(vc)
+	vc.Speaking(true)
+	return nil
+}
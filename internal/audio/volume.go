@@ -0,0 +1,54 @@
+package audio
+
+import "math"
+
+// maxGainStep caps how far the gain can move within one 20ms frame, so a jump
+// from 100% to 20% is spread over a few frames instead of clicking.
+const maxGainStep = 0.2
+
+// gainRamp applies the session volume to PCM frames in-process, smoothing
+// changes across frame boundaries.
+type gainRamp struct {
+	current float64
+}
+
+func newGainRamp(gain float64) *gainRamp {
+	return &gainRamp{current: gain}
+}
+
+func (g *gainRamp) apply(pcm []int16, target float64) {
+	start := g.current
+	end := target
+	if end-start > maxGainStep {
+		end = start + maxGainStep
+	} else if start-end > maxGainStep {
+		end = start - maxGainStep
+	}
+	g.current = end
+
+	if start == 1 && end == 1 {
+		return
+	}
+
+	samples := len(pcm) / channels
+	step := (end - start) / float64(samples)
+	gain := start
+
+	for i := 0; i < samples; i++ {
+		gain += step
+		for c := 0; c < channels; c++ {
+			idx := i*channels + c
+			pcm[idx] = clampSample(float64(pcm[idx]) * gain)
+		}
+	}
+}
+
+func clampSample(v float64) int16 {
+	if v > math.MaxInt16 {
+		return math.MaxInt16
+	}
+	if v < math.MinInt16 {
+		return math.MinInt16
+	}
+	return int16(v)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/dickeyy/meow/internal/audio"
@@ -26,6 +27,11 @@ type Bot struct {
 	artwork    *artwork.ITunesClient
 	storage    *storage.Storage
 	commands   *commands.Registry
+
+	resumeOnce sync.Once       // Saved sessions are only offered on the first ready
+	stopping   atomic.Bool     // Keeps saved sessions from being cleared on shutdown
+	saved      map[string]bool // Guilds with a session saved by this run
+	savedMu    sync.Mutex
 }
 
 func New(ctx context.Context, cfg *config.Config) (*Bot, error) {
@@ -43,6 +49,7 @@ func New(ctx context.Context, cfg *config.Config) (*Bot, error) {
 		config:   cfg,
 		ctx:      ctx,
 		sessions: make(map[string]*audio.Session),
+		saved:    make(map[string]bool),
 		youtube:  youtube.NewExtractorWithCookies(cfg.YouTubeCookiesPath),
 		artwork:  artwork.NewITunesClient(),
 	}
@@ -58,6 +65,7 @@ func New(ctx context.Context, cfg *config.Config) (*Bot, error) {
 		if err != nil {
 			fmt.Printf("Warning: Failed to initialize storage: %v\n", err)
 		} else {
+			store.SetDefaultVolume(cfg.DefaultVolume)
 			b.storage = store
 		}
 	}
@@ -69,6 +77,7 @@ func New(ctx context.Context, cfg *config.Config) (*Bot, error) {
 	session.AddHandler(b.handleReady)
 	session.AddHandler(b.handleInteractionCreate)
 	session.AddHandler(b.handleVoiceStateUpdate)
+	session.AddHandler(b.handleChannelUpdate)
 
 	return b, nil
 }
@@ -83,10 +92,17 @@ func (b *Bot) Start() error {
 		return fmt.Errorf("failed to register commands: %w", err)
 	}
 
+	go b.persistSessions()
+	go b.watchIdle()
+
 	return nil
 }
 
 func (b *Bot) Stop() {
+	// Save everyone's queue so it can be resumed after the restart
+	b.saveSessions()
+	b.stopping.Store(true)
+
 	// Disconnect from all voice channels
 	b.sessionsMu.Lock()
 	for _, s := range b.sessions {
@@ -112,15 +128,57 @@ func (b *Bot) GetSession(guildID string) *audio.Session {
 }
 
 func (b *Bot) GetOrCreateSession(guildID string) *audio.Session {
+	if s := b.GetSession(guildID); s != nil {
+		return s
+	}
+
+	// Loading the guild's settings can mean a database round trip, so it's
+	// done before taking the lock that every guild's commands go through
+	s := b.newSession(guildID)
+
 	b.sessionsMu.Lock()
 	defer b.sessionsMu.Unlock()
 
-	if s, exists := b.sessions[guildID]; exists {
-		return s
+	// Another command may have created one meanwhile
+	if existing, exists := b.sessions[guildID]; exists {
+		return existing
 	}
+	b.sessions[guildID] = s
+	return s
+}
 
+// newSession creates a session set up with the guild's saved settings
+func (b *Bot) newSession(guildID string) *audio.Session {
 	s := audio.NewSession(guildID, b.config.DefaultVolume)
-	b.sessions[guildID] = s
+
+	encoder := audio.EncoderSettings{
+		Bitrate:     b.config.OpusBitrate * 1000,
+		Application: audio.Application(b.config.OpusApplication),
+		FEC:         b.config.OpusFEC,
+		PacketLoss:  b.config.OpusPacketLoss,
+	}
+
+	if b.storage != nil {
+		if settings, err := b.storage.GetGuildSettings(guildID); err == nil {
+			s.SetVolume(settings.DefaultVolume)
+			s.SetNormalize(settings.Normalize)
+
+			if settings.Bitrate > 0 {
+				encoder.Bitrate = settings.Bitrate * 1000
+			}
+			if app, ok := audio.ParseApplication(settings.Application); ok {
+				encoder.Application = app
+			}
+			if settings.FEC != nil {
+				encoder.FEC = *settings.FEC
+			}
+			if settings.PacketLoss != nil {
+				encoder.PacketLoss = *settings.PacketLoss
+			}
+		}
+	}
+
+	s.SetEncoder(encoder)
 	return s
 }
 
@@ -132,6 +190,13 @@ func (b *Bot) RemoveSession(guildID string) {
 		s.Stop()
 		delete(b.sessions, guildID)
 	}
+
+	// The bot left the channel, so there's nothing to resume
+	if b.storage != nil && !b.stopping.Load() {
+		if err := b.storage.DeleteSavedSession(guildID); err != nil {
+			fmt.Printf("Failed to delete saved session for %s: %v\n", guildID, err)
+		}
+	}
 }
 
 func (b *Bot) Discord() *discordgo.Session {
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/dickeyy/meow/internal/commands"
 )
 
 func (b *Bot) handleReady(s *discordgo.Session, r *discordgo.Ready) {
@@ -21,6 +22,11 @@ func (b *Bot) handleReady(s *discordgo.Session, r *discordgo.Ready) {
 	if err != nil {
 		fmt.Printf("Failed to update status: %v\n", err)
 	}
+
+	// Ready fires again after a full reconnect, which isn't a restart
+	b.resumeOnce.Do(func() {
+		go b.offerResume()
+	})
 }
 
 func (b *Bot) handleInteractionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
@@ -33,6 +39,13 @@ func (b *Bot) handleInteractionCreate(s *discordgo.Session, i *discordgo.Interac
 }
 
 func (b *Bot) handleVoiceStateUpdate(s *discordgo.Session, v *discordgo.VoiceStateUpdate) {
+	session := b.GetSession(v.GuildID)
+
+	// The player briefly leaves while rejoining after a dropped connection
+	if session != nil && session.Reconnecting() {
+		return
+	}
+
 	// Check if it's the bot leaving a voice channel
 	if v.UserID == s.State.User.ID && v.ChannelID == "" {
 		b.RemoveSession(v.GuildID)
@@ -40,7 +53,6 @@ func (b *Bot) handleVoiceStateUpdate(s *discordgo.Session, v *discordgo.VoiceSta
 	}
 
 	// Check if bot is alone in voice channel
-	session := b.GetSession(v.GuildID)
 	if session == nil {
 		return
 	}
@@ -69,6 +81,23 @@ func (b *Bot) handleVoiceStateUpdate(s *discordgo.Session, v *discordgo.VoiceSta
 		session.Stop()
 		vc.Disconnect()
 		b.RemoveSession(v.GuildID)
+		return
 	}
+
+	// A vote to skip may pass, or need fewer votes, with people gone
+	commands.RecountSkipVote(s, b, session)
 }
 
+
+// handleChannelUpdate keeps the encoder in line with the voice channel's
+// bitrate when it is changed mid-session
+func (b *Bot) handleChannelUpdate(s *discordgo.Session, c *discordgo.ChannelUpdate) {
+	session := b.GetSession(c.GuildID)
+	if session == nil {
+		return
+	}
+
+	if vc := session.VoiceConnection(); vc != nil && vc.ChannelID == c.ID {
+		session.SetChannelBitrate(c.Bitrate)
+	}
+}
@@ -0,0 +1,88 @@
+package bot
+
+import (
+	"fmt"
+	"time"
+)
+
+const idleCheckInterval = 30 * time.Second
+
+// watchIdle leaves voice channels where nothing has played for longer than
+// the guild's idle timeout, until the bot shuts down
+func (b *Bot) watchIdle() {
+	ticker := time.NewTicker(idleCheckInterval)
+	defer ticker.Stop()
+
+	// When each idle session was first seen with nothing playing
+	idleSince := make(map[string]time.Time)
+
+	for {
+		select {
+		case <-b.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		b.sessionsMu.RLock()
+		idle := make(map[string]bool)
+		for guildID, s := range b.sessions {
+			if s.IsStopped() && s.VoiceConnection() != nil {
+				idle[guildID] = true
+			}
+		}
+		b.sessionsMu.RUnlock()
+
+		for guildID := range idleSince {
+			if !idle[guildID] {
+				delete(idleSince, guildID)
+			}
+		}
+
+		for guildID := range idle {
+			since, seen := idleSince[guildID]
+			if !seen {
+				idleSince[guildID] = time.Now()
+				continue
+			}
+
+			timeout := b.idleTimeout(guildID)
+			if timeout == 0 || time.Since(since) < timeout {
+				continue
+			}
+
+			fmt.Printf("Leaving voice in guild %s after %s idle\n", guildID, timeout)
+			delete(idleSince, guildID)
+			b.leaveVoice(guildID)
+		}
+	}
+}
+
+// idleTimeout is how long the guild lets the bot sit in voice with nothing
+// playing, or 0 to stay
+func (b *Bot) idleTimeout(guildID string) time.Duration {
+	if b.storage == nil {
+		return 0
+	}
+
+	settings, err := b.storage.GetGuildSettings(guildID)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(settings.IdleTimeout) * time.Minute
+}
+
+func (b *Bot) leaveVoice(guildID string) {
+	session := b.GetSession(guildID)
+	if session == nil {
+		return
+	}
+
+	// Check again, something may have started since
+	vc := session.VoiceConnection()
+	if vc == nil || !session.IsStopped() {
+		return
+	}
+
+	vc.Disconnect()
+	b.RemoveSession(guildID)
+}
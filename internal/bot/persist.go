@@ -0,0 +1,93 @@
+package bot
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dickeyy/meow/internal/commands"
+)
+
+const (
+	// How often playing sessions are saved, so a crash loses at most this
+	// much of everyone's position
+	snapshotInterval = 30 * time.Second
+
+	// Saved sessions older than this aren't worth offering to resume
+	snapshotMaxAge = 24 * time.Hour
+)
+
+// persistSessions saves every playing session periodically until the bot
+// shuts down
+func (b *Bot) persistSessions() {
+	ticker := time.NewTicker(snapshotInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.ctx.Done():
+			return
+		case <-ticker.C:
+			b.saveSessions()
+		}
+	}
+}
+
+// saveSessions snapshots every active session and forgets the ones that
+// have finished
+func (b *Bot) saveSessions() {
+	if b.storage == nil || b.stopping.Load() {
+		return
+	}
+
+	b.sessionsMu.RLock()
+	defer b.sessionsMu.RUnlock()
+
+	b.savedMu.Lock()
+	defer b.savedMu.Unlock()
+
+	for guildID, s := range b.sessions {
+		saved := commands.SnapshotSession(s)
+		if saved == nil {
+			// Only clear what this run saved, so a session from before the
+			// restart can still be resumed
+			if b.saved[guildID] {
+				if err := b.storage.DeleteSavedSession(guildID); err != nil {
+					fmt.Printf("Failed to delete saved session for %s: %v\n", guildID, err)
+				}
+				delete(b.saved, guildID)
+			}
+			continue
+		}
+
+		if err := b.storage.SaveSession(saved); err != nil {
+			fmt.Printf("Failed to save session for %s: %v\n", guildID, err)
+			continue
+		}
+		b.saved[guildID] = true
+	}
+}
+
+// offerResume asks each guild that was playing before the restart whether
+// to pick up where it left off
+func (b *Bot) offerResume() {
+	if b.storage == nil {
+		return
+	}
+
+	saved, err := b.storage.GetSavedSessions()
+	if err != nil {
+		fmt.Printf("Failed to load saved sessions: %v\n", err)
+		return
+	}
+
+	for _, s := range saved {
+		if time.Since(s.SavedAt) > snapshotMaxAge || len(s.Tracks) == 0 || s.TextChannelID == "" {
+			b.storage.DeleteSavedSession(s.GuildID)
+			continue
+		}
+
+		if err := commands.OfferResume(b.session, s); err != nil {
+			fmt.Printf("Failed to offer resume in %s: %v\n", s.GuildID, err)
+		}
+	}
+}
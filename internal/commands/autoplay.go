@@ -0,0 +1,135 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dickeyy/meow/internal/audio"
+	"github.com/dickeyy/meow/internal/embeds"
+)
+
+const (
+	// Tracks added each time the queue runs out
+	autoplayBatch = 5
+	// How many of the last played tracks seed recommendations
+	autoplaySeeds = 3
+	// Recommendations matching any of this many recent tracks are skipped
+	autoplayHistoryWindow = 50
+)
+
+func handleAutoplay(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInterface) {
+	session := bot.GetSession(i.GuildID)
+	if session == nil {
+		respond(s, i, embeds.Error("Error", "No active session. Start playing something first."))
+		return
+	}
+
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		respond(s, i, embeds.Info("Autoplay", fmt.Sprintf("Autoplay: **%s**", onOff(session.Autoplay()))))
+		return
+	}
+
+	session.SetAutoplay(options[0].BoolValue())
+
+	if session.Autoplay() {
+		respond(s, i, embeds.Success("Autoplay", "Related tracks will keep playing when the queue runs out"))
+	} else {
+		respond(s, i, embeds.Success("Autoplay", "Autoplay disabled"))
+	}
+}
+
+// relatedTracks picks tracks to keep playing once the queue runs out, seeded
+// from the last few tracks played
+func relatedTracks(bot BotInterface, history []*audio.Track) []*audio.Track {
+	if len(history) == 0 {
+		return nil
+	}
+
+	seeds := history[max(0, len(history)-autoplaySeeds):]
+	recent := history[max(0, len(history)-autoplayHistoryWindow):]
+
+	var candidates []*audio.Track
+
+	// Spotify seeds get Spotify's own recommendations
+	if bot.Spotify() != nil {
+		var seedIDs []string
+		for _, track := range seeds {
+			if track.Source == audio.SourceSpotify && track.ID != "" {
+				seedIDs = append(seedIDs, track.ID)
+			}
+		}
+
+		if len(seedIDs) > 0 {
+			tracks, err := bot.Spotify().Recommend(seedIDs, autoplayBatch*2, "")
+			if err != nil {
+				fmt.Printf("[autoplay] Spotify recommendations failed: %v\n", err)
+			}
+			candidates = tracks
+		}
+	}
+
+	// Otherwise fall back to YouTube's mix for the most recent track
+	if len(candidates) == 0 {
+		videoID, err := youtubeSeed(bot, seeds[len(seeds)-1])
+		if err != nil {
+			fmt.Printf("[autoplay] Failed to find a seed video: %v\n", err)
+			return nil
+		}
+
+		candidates, err = bot.YouTube().Related(videoID, autoplayBatch*2, "")
+		if err != nil {
+			fmt.Printf("[autoplay] Failed to get related tracks: %v\n", err)
+			return nil
+		}
+	}
+
+	tracks := withoutRepeats(candidates, recent, autoplayBatch)
+	fmt.Printf("[autoplay] Queueing %d related tracks\n", len(tracks))
+	return tracks
+}
+
+// youtubeSeed finds a YouTube video ID to base a mix on
+func youtubeSeed(bot BotInterface, track *audio.Track) (string, error) {
+	if track.Source == audio.SourceYouTube && track.ID != "" {
+		return track.ID, nil
+	}
+
+	result, err := bot.YouTube().Search(track.Artist+" "+track.Title, "")
+	if err != nil {
+		return "", err
+	}
+	return result.ID, nil
+}
+
+// withoutRepeats drops candidates already in recent (or repeated among
+// themselves) and returns at most limit of the rest
+func withoutRepeats(candidates, recent []*audio.Track, limit int) []*audio.Track {
+	seen := make(map[string]bool)
+	for _, track := range recent {
+		seen[track.ID] = true
+		seen[trackKey(track)] = true
+	}
+
+	var result []*audio.Track
+	for _, track := range candidates {
+		if len(result) >= limit {
+			break
+		}
+		if seen[track.ID] || seen[trackKey(track)] {
+			continue
+		}
+		seen[track.ID] = true
+		seen[trackKey(track)] = true
+		result = append(result, track)
+	}
+
+	return result
+}
+
+// trackKey identifies a song across sources, so a Spotify track and its
+// YouTube upload count as the same thing
+func trackKey(track *audio.Track) string {
+	return strings.ToLower(strings.TrimSpace(track.Artist) + " - " + strings.TrimSpace(track.Title))
+}
@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dickeyy/meow/internal/embeds"
+)
+
+func handleCrossfade(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInterface) {
+	session := bot.GetSession(i.GuildID)
+	if session == nil {
+		respond(s, i, embeds.Error("Error", "No active session. Start playing something first."))
+		return
+	}
+
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		respond(s, i, embeds.Info("Crossfade", fmt.Sprintf("Crossfade: **%ds**", int(session.Crossfade().Seconds()))))
+		return
+	}
+
+	session.SetCrossfade(time.Duration(options[0].IntValue()) * time.Second)
+
+	if session.Crossfade() == 0 {
+		respond(s, i, embeds.Success("Crossfade", "Crossfade disabled"))
+		return
+	}
+	respond(s, i, embeds.Success("Crossfade", fmt.Sprintf("Tracks will crossfade over **%ds**", int(session.Crossfade().Seconds()))))
+}
+
+func handleGapless(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInterface) {
+	session := bot.GetSession(i.GuildID)
+	if session == nil {
+		respond(s, i, embeds.Error("Error", "No active session. Start playing something first."))
+		return
+	}
+
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		respond(s, i, embeds.Info("Gapless", fmt.Sprintf("Gapless playback: **%s**", onOff(session.Gapless()))))
+		return
+	}
+
+	session.SetGapless(options[0].BoolValue())
+	respond(s, i, embeds.Success("Gapless", fmt.Sprintf("Gapless playback: **%s**", onOff(session.Gapless()))))
+}
+
+func onOff(enabled bool) string {
+	if enabled {
+		return "on"
+	}
+	return "off"
+}
@@ -0,0 +1,119 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dickeyy/meow/internal/audio"
+	"github.com/dickeyy/meow/internal/embeds"
+	"github.com/dickeyy/meow/internal/storage"
+)
+
+func handleEncoder(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInterface) {
+	options := i.ApplicationCommandData().Options
+
+	// Without options just show what's in use
+	if len(options) == 0 {
+		respond(s, i, embeds.Info("Encoder", describeEncoder(bot.GetOrCreateSession(i.GuildID))))
+		return
+	}
+
+	store := bot.Storage()
+	var settings *storage.GuildSettings
+	if store != nil && store.Persistent() {
+		loaded, err := store.GetGuildSettings(i.GuildID)
+		if err != nil {
+			respond(s, i, embeds.Error("Error", "Failed to load guild settings"))
+			return
+		}
+		settings = loaded
+	}
+
+	session := bot.GetOrCreateSession(i.GuildID)
+	encoder := session.Encoder()
+
+	for _, opt := range options {
+		switch opt.Name {
+		case "bitrate":
+			kbps := int(opt.IntValue())
+			if kbps == 0 {
+				// Back to the bot's default
+				encoder.Bitrate = bot.Config().OpusBitrate * 1000
+			} else {
+				encoder.Bitrate = kbps * 1000
+			}
+			if settings != nil {
+				settings.Bitrate = kbps
+			}
+
+		case "application":
+			app, ok := audio.ParseApplication(opt.StringValue())
+			if !ok {
+				respond(s, i, embeds.Error("Error", "Unknown application mode"))
+				return
+			}
+			encoder.Application = app
+			if settings != nil {
+				settings.Application = string(app)
+			}
+
+		case "fec":
+			fec := opt.BoolValue()
+			encoder.FEC = fec
+			if settings != nil {
+				settings.FEC = &fec
+			}
+
+		case "packet-loss":
+			loss := int(opt.IntValue())
+			encoder.PacketLoss = loss
+			if settings != nil {
+				settings.PacketLoss = &loss
+			}
+		}
+	}
+
+	if settings != nil {
+		if err := store.SaveGuildSettings(settings); err != nil {
+			fmt.Printf("[encoder] Failed to save settings: %v\n", err)
+			respond(s, i, embeds.Error("Error", "Failed to save settings"))
+			return
+		}
+	}
+
+	session.SetEncoder(encoder)
+	respond(s, i, embeds.Success("Encoder", describeEncoder(session)))
+}
+
+func describeEncoder(session *audio.Session) string {
+	encoder := session.Encoder()
+
+	bitrate := fmt.Sprintf("%d kbps", session.Bitrate()/1000)
+	if encoder.Bitrate == 0 {
+		bitrate += " (matching the voice channel)"
+	} else if channel := session.ChannelBitrate(); channel > 0 && channel < encoder.Bitrate {
+		bitrate += fmt.Sprintf(" (capped from %d kbps by the voice channel)", encoder.Bitrate/1000)
+	}
+
+	fec := "Off"
+	if encoder.FEC {
+		fec = "On"
+		if encoder.PacketLoss == 0 {
+			fec += " (unused until packet loss is above 0%)"
+		}
+	}
+
+	return fmt.Sprintf("**Bitrate:** %s\n**Application:** %s\n**FEC:** %s\n**Expected packet loss:** %d%%",
+		bitrate, encoder.Application, fec, encoder.PacketLoss)
+}
+
+func applicationChoices() []*discordgo.ApplicationCommandOptionChoice {
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, len(audio.Applications))
+	for i, app := range audio.Applications {
+		choices[i] = &discordgo.ApplicationCommandOptionChoice{
+			Name:  app.String(),
+			Value: string(app),
+		}
+	}
+	return choices
+}
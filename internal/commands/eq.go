@@ -0,0 +1,247 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dickeyy/meow/internal/audio"
+	"github.com/dickeyy/meow/internal/embeds"
+)
+
+const (
+	maxEQPresets       = 25
+	maxEQPresetNameLen = 32
+)
+
+func handleEQ(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInterface) {
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		respond(s, i, embeds.Error("Error", "Please specify a subcommand"))
+		return
+	}
+
+	subCmd := options[0]
+
+	switch subCmd.Name {
+	case "view":
+		handleEQView(s, i, bot)
+	case "set":
+		handleEQSet(s, i, bot, subCmd.Options)
+	case "reset":
+		handleEQReset(s, i, bot)
+	case "save":
+		handleEQSave(s, i, bot, subCmd.Options)
+	case "load":
+		handleEQLoad(s, i, bot, subCmd.Options)
+	case "delete":
+		handleEQDelete(s, i, bot, subCmd.Options)
+	}
+}
+
+func handleEQView(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInterface) {
+	session := bot.GetSession(i.GuildID)
+
+	var bands audio.EQBands
+	if session != nil {
+		bands = session.EQ()
+	}
+
+	description := formatEQ(bands)
+
+	if store := bot.Storage(); store != nil && store.Persistent() {
+		settings, err := store.GetGuildSettings(i.GuildID)
+		if err == nil && len(settings.EQPresets) > 0 {
+			names := make([]string, 0, len(settings.EQPresets))
+			for name := range settings.EQPresets {
+				names = append(names, "`"+name+"`")
+			}
+			sort.Strings(names)
+			description += "\n**Saved presets:** " + strings.Join(names, ", ")
+		}
+	}
+
+	respond(s, i, embeds.Info("Equalizer", description))
+}
+
+func handleEQSet(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInterface, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	session := bot.GetSession(i.GuildID)
+	if session == nil {
+		respond(s, i, embeds.Error("Error", "No active session. Start playing something first."))
+		return
+	}
+
+	band := -1
+	var gain float64
+	for _, opt := range options {
+		switch opt.Name {
+		case "band":
+			band = int(opt.IntValue())
+		case "gain":
+			gain = opt.FloatValue()
+		}
+	}
+
+	if band < 0 || band >= audio.EQBandCount {
+		respond(s, i, embeds.Error("Error", "Invalid band"))
+		return
+	}
+
+	session.SetEQBand(band, gain)
+	respond(s, i, embeds.Success("Equalizer", fmt.Sprintf("Set **%s** to **%+.1f dB**\n\n%s",
+		audio.FormatEQFrequency(band),
+		session.EQ()[band],
+		formatEQ(session.EQ()),
+	)))
+}
+
+func handleEQReset(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInterface) {
+	session := bot.GetSession(i.GuildID)
+	if session == nil {
+		respond(s, i, embeds.Error("Error", "No active session. Start playing something first."))
+		return
+	}
+
+	session.SetEQ(audio.EQBands{})
+	respond(s, i, embeds.Success("Equalizer", "Equalizer reset to flat"))
+}
+
+func handleEQSave(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInterface, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	session := bot.GetSession(i.GuildID)
+	if session == nil {
+		respond(s, i, embeds.Error("Error", "No active session. Start playing something first."))
+		return
+	}
+
+	store := bot.Storage()
+	if store == nil || !store.Persistent() {
+		respond(s, i, embeds.Error("Error", "Saving presets requires a database"))
+		return
+	}
+
+	name := presetNameOption(options)
+	if name == "" || len(name) > maxEQPresetNameLen {
+		respond(s, i, embeds.Error("Error", fmt.Sprintf("Preset names must be 1-%d characters", maxEQPresetNameLen)))
+		return
+	}
+
+	settings, err := store.GetGuildSettings(i.GuildID)
+	if err != nil {
+		respond(s, i, embeds.Error("Error", "Failed to load guild settings"))
+		return
+	}
+
+	if _, exists := settings.EQPresets[name]; !exists && len(settings.EQPresets) >= maxEQPresets {
+		respond(s, i, embeds.Error("Error", fmt.Sprintf("This server already has %d presets. Delete one first.", maxEQPresets)))
+		return
+	}
+
+	bands := session.EQ()
+	settings.EQPresets[name] = bands[:]
+
+	if err := store.SaveGuildSettings(settings); err != nil {
+		fmt.Printf("[eq] Failed to save preset: %v\n", err)
+		respond(s, i, embeds.Error("Error", "Failed to save preset"))
+		return
+	}
+
+	respond(s, i, embeds.Success("Equalizer", fmt.Sprintf("Saved preset **%s**", name)))
+}
+
+func handleEQLoad(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInterface, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	session := bot.GetSession(i.GuildID)
+	if session == nil {
+		respond(s, i, embeds.Error("Error", "No active session. Start playing something first."))
+		return
+	}
+
+	store := bot.Storage()
+	if store == nil || !store.Persistent() {
+		respond(s, i, embeds.Error("Error", "Saved presets require a database"))
+		return
+	}
+
+	name := presetNameOption(options)
+
+	settings, err := store.GetGuildSettings(i.GuildID)
+	if err != nil {
+		respond(s, i, embeds.Error("Error", "Failed to load guild settings"))
+		return
+	}
+
+	saved, exists := settings.EQPresets[name]
+	if !exists {
+		respond(s, i, embeds.Error("Error", fmt.Sprintf("No preset named **%s**", name)))
+		return
+	}
+
+	var bands audio.EQBands
+	copy(bands[:], saved)
+	session.SetEQ(bands)
+
+	respond(s, i, embeds.Success("Equalizer", fmt.Sprintf("Loaded preset **%s**\n\n%s", name, formatEQ(session.EQ()))))
+}
+
+func handleEQDelete(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInterface, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	store := bot.Storage()
+	if store == nil || !store.Persistent() {
+		respond(s, i, embeds.Error("Error", "Saved presets require a database"))
+		return
+	}
+
+	name := presetNameOption(options)
+
+	settings, err := store.GetGuildSettings(i.GuildID)
+	if err != nil {
+		respond(s, i, embeds.Error("Error", "Failed to load guild settings"))
+		return
+	}
+
+	if _, exists := settings.EQPresets[name]; !exists {
+		respond(s, i, embeds.Error("Error", fmt.Sprintf("No preset named **%s**", name)))
+		return
+	}
+
+	delete(settings.EQPresets, name)
+
+	if err := store.SaveGuildSettings(settings); err != nil {
+		fmt.Printf("[eq] Failed to delete preset: %v\n", err)
+		respond(s, i, embeds.Error("Error", "Failed to delete preset"))
+		return
+	}
+
+	respond(s, i, embeds.Success("Equalizer", fmt.Sprintf("Deleted preset **%s**", name)))
+}
+
+func presetNameOption(options []*discordgo.ApplicationCommandInteractionDataOption) string {
+	for _, opt := range options {
+		if opt.Name == "name" {
+			return strings.ToLower(strings.TrimSpace(opt.StringValue()))
+		}
+	}
+	return ""
+}
+
+func formatEQ(bands audio.EQBands) string {
+	if bands.IsFlat() {
+		return "All bands flat"
+	}
+
+	lines := make([]string, audio.EQBandCount)
+	for band, gain := range bands {
+		lines[band] = fmt.Sprintf("`%7s` %+.1f dB", audio.FormatEQFrequency(band), gain)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func eqBandChoices() []*discordgo.ApplicationCommandOptionChoice {
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, audio.EQBandCount)
+	for band := range choices {
+		choices[band] = &discordgo.ApplicationCommandOptionChoice{
+			Name:  audio.FormatEQFrequency(band),
+			Value: band,
+		}
+	}
+	return choices
+}
@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dickeyy/meow/internal/audio"
+	"github.com/dickeyy/meow/internal/embeds"
+)
+
+func handleFilter(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInterface) {
+	session := bot.GetSession(i.GuildID)
+	if session == nil {
+		respond(s, i, embeds.Error("Error", "No active session. Start playing something first."))
+		return
+	}
+
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		respond(s, i, embeds.Error("Error", "Please specify a subcommand"))
+		return
+	}
+
+	subCmd := options[0]
+
+	switch subCmd.Name {
+	case "enable":
+		filter, ok := filterOption(subCmd.Options)
+		if !ok {
+			respond(s, i, embeds.Error("Error", "Unknown filter"))
+			return
+		}
+		session.EnableFilter(filter)
+		respond(s, i, embeds.Success("Filters", "Enabled **"+filter.String()+"**\n"+describeFilters(session)))
+	case "disable":
+		filter, ok := filterOption(subCmd.Options)
+		if !ok {
+			respond(s, i, embeds.Error("Error", "Unknown filter"))
+			return
+		}
+		session.DisableFilter(filter)
+		respond(s, i, embeds.Success("Filters", "Disabled **"+filter.String()+"**\n"+describeFilters(session)))
+	case "clear":
+		session.ClearFilters()
+		respond(s, i, embeds.Success("Filters", "All filters cleared"))
+	}
+}
+
+func filterOption(options []*discordgo.ApplicationCommandInteractionDataOption) (audio.Filter, bool) {
+	for _, opt := range options {
+		if opt.Name == "preset" {
+			return audio.ParseFilter(opt.StringValue())
+		}
+	}
+	return "", false
+}
+
+func describeFilters(session *audio.Session) string {
+	filters := session.Filters()
+	if len(filters) == 0 {
+		return "No filters active"
+	}
+
+	names := make([]string, len(filters))
+	for i, f := range filters {
+		names[i] = f.String()
+	}
+	return "Active: " + strings.Join(names, ", ")
+}
+
+func filterChoices() []*discordgo.ApplicationCommandOptionChoice {
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, len(audio.Filters))
+	for i, f := range audio.Filters {
+		choices[i] = &discordgo.ApplicationCommandOptionChoice{
+			Name:  f.String(),
+			Value: string(f),
+		}
+	}
+	return choices
+}
@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"github.com/dickeyy/meow/internal/audio"
+	"github.com/dickeyy/meow/internal/embeds"
+)
+
+func handleLoop(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInterface) {
+	session := bot.GetSession(i.GuildID)
+	if session == nil {
+		respond(s, i, embeds.Error("Error", "No active session. Start playing something first."))
+		return
+	}
+
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		respond(s, i, embeds.Info("Loop", "Loop mode: **"+session.LoopMode().String()+"**"))
+		return
+	}
+
+	mode, ok := audio.ParseLoopMode(options[0].StringValue())
+	if !ok {
+		respond(s, i, embeds.Error("Error", "Loop mode must be track, queue or off"))
+		return
+	}
+
+	session.SetLoopMode(mode)
+	respond(s, i, embeds.Success("Loop", loopDescription(mode)))
+}
+
+func loopDescription(mode audio.LoopMode) string {
+	switch mode {
+	case audio.LoopTrack:
+		return "Looping the current track"
+	case audio.LoopQueue:
+		return "Looping the queue"
+	default:
+		return "Looping disabled"
+	}
+}
+
+// Component handler for the loop button, cycling off -> track -> queue
+func handlePlayerLoop(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInterface) {
+	session := bot.GetSession(i.GuildID)
+	if session == nil || session.IsStopped() {
+		respondComponent(s, i, embeds.Error("Error", "Nothing is playing"))
+		return
+	}
+
+	session.SetLoopMode(session.LoopMode().Cycle())
+
+	track := session.Queue().Current()
+	if track != nil {
+		updateNowPlaying(s, i, track, session)
+	} else {
+		acknowledgeComponent(s, i)
+	}
+}
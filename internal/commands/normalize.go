@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dickeyy/meow/internal/embeds"
+)
+
+func handleNormalize(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInterface) {
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		respond(s, i, embeds.Error("Error", "Please specify whether normalization is enabled"))
+		return
+	}
+
+	enabled := options[0].BoolValue()
+
+	if store := bot.Storage(); store != nil && store.Persistent() {
+		settings, err := store.GetGuildSettings(i.GuildID)
+		if err != nil {
+			respond(s, i, embeds.Error("Error", "Failed to load guild settings"))
+			return
+		}
+
+		settings.Normalize = enabled
+		if err := store.SaveGuildSettings(settings); err != nil {
+			fmt.Printf("[normalize] Failed to save setting: %v\n", err)
+			respond(s, i, embeds.Error("Error", "Failed to save setting"))
+			return
+		}
+	}
+
+	if session := bot.GetSession(i.GuildID); session != nil {
+		session.SetNormalize(enabled)
+
+		// Measure the current and upcoming tracks now rather than waiting
+		// for them to be resolved again
+		if enabled {
+			if track := session.Queue().Current(); track != nil {
+				loadLoudness(bot, session, track)
+			}
+			for _, track := range session.Queue().Peek(bot.Config().PrefetchDepth) {
+				if !track.NeedsResolve() {
+					loadLoudness(bot, session, track)
+				}
+			}
+		}
+	}
+
+	if enabled {
+		respond(s, i, embeds.Success("Normalization", "Tracks will play at a consistent loudness"))
+	} else {
+		respond(s, i, embeds.Success("Normalization", "Loudness normalization disabled"))
+	}
+}
@@ -0,0 +1,201 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dickeyy/meow/internal/embeds"
+	"github.com/dickeyy/meow/internal/storage"
+)
+
+// djCommands are the commands that change playback for everyone, keyed by
+// name and subcommand. Once a server sets a DJ role only DJs can use them.
+// Skipping checks for itself, since it can be put to a vote.
+var djCommands = map[string]bool{
+	"pause":          true,
+	"resume":         true,
+	"previous":       true,
+	"stop":           true,
+	"seek":           true,
+	"volume":         true,
+	"shuffle":        true,
+	"loop":           true,
+	"queue move":     true,
+	"queue remove":   true,
+	"queue clear":    true,
+	"filter enable":  true,
+	"filter disable": true,
+	"filter clear":   true,
+	"eq set":         true,
+	"eq reset":       true,
+	"eq load":        true,
+	"eq save":        true,
+	"eq delete":      true,
+	"crossfade":      true,
+	"gapless":        true,
+	"normalize":      true,
+	"encoder":        true,
+	"speed":          true,
+	"pitch":          true,
+	"autoplay":       true,
+}
+
+// viewableCommands only show the current setting when used without
+// options, so anyone may do that even though changing it needs a DJ
+var viewableCommands = map[string]bool{
+	"volume":    true,
+	"loop":      true,
+	"crossfade": true,
+	"gapless":   true,
+	"encoder":   true,
+	"speed":     true,
+	"pitch":     true,
+	"autoplay":  true,
+}
+
+// djComponents are the now playing buttons that need the DJ role
+var djComponents = map[string]bool{
+	"player_pause":    true,
+	"player_resume":   true,
+	"player_previous": true,
+	"player_stop":     true,
+	"player_rewind":   true,
+	"player_forward":  true,
+	"player_loop":     true,
+}
+
+// savedSessionComponents are the buttons offering to resume a session
+// saved before a restart. Nothing is playing yet, so there's no requester
+// or listeners to go by and only the DJ role counts.
+var savedSessionComponents = map[string]bool{
+	"session_resume":  true,
+	"session_dismiss": true,
+}
+
+// commandKey names a command with its subcommand, e.g. "queue clear"
+func commandKey(data discordgo.ApplicationCommandInteractionData) string {
+	if len(data.Options) > 0 && data.Options[0].Type == discordgo.ApplicationCommandOptionSubCommand {
+		return data.Name + " " + data.Options[0].Name
+	}
+	return data.Name
+}
+
+// needsDJ reports whether using a command takes the DJ role
+func needsDJ(data discordgo.ApplicationCommandInteractionData) bool {
+	if viewableCommands[data.Name] && len(data.Options) == 0 {
+		return false
+	}
+	return djCommands[commandKey(data)]
+}
+
+// isDJ reports whether the user may control playback. Everyone can if the
+// server hasn't set a DJ role.
+func isDJ(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInterface) bool {
+	settings := guildSettings(bot, i.GuildID)
+	if settings == nil || settings.DJRoleID == "" {
+		return true
+	}
+	return hasDJRights(s, i, bot, settings)
+}
+
+// hasDJRole reports whether the user has the DJ role or Manage Server.
+// Everyone does if the server hasn't set a DJ role.
+func hasDJRole(i *discordgo.InteractionCreate, bot BotInterface) bool {
+	settings := guildSettings(bot, i.GuildID)
+	if settings == nil || settings.DJRoleID == "" || i.Member == nil || canManageServer(i) {
+		return true
+	}
+	for _, role := range i.Member.Roles {
+		if role == settings.DJRoleID {
+			return true
+		}
+	}
+	return false
+}
+
+// hasDJRights reports whether the user counts as a DJ: they have the DJ
+// role or Manage Server, requested the current track, or are alone with
+// the bot
+func hasDJRights(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInterface, settings *storage.GuildSettings) bool {
+	if i.Member == nil || canManageServer(i) {
+		return true
+	}
+
+	if settings.DJRoleID != "" {
+		for _, role := range i.Member.Roles {
+			if role == settings.DJRoleID {
+				return true
+			}
+		}
+	}
+
+	session := bot.GetSession(i.GuildID)
+	if session == nil {
+		return true
+	}
+	if current := session.Queue().Current(); current != nil && current.RequestedBy == i.Member.User.ID {
+		return true
+	}
+
+	vc := session.VoiceConnection()
+	if vc == nil {
+		return true
+	}
+	listeners := voiceListeners(s, i.GuildID, vc.ChannelID)
+	return len(listeners) == 1 && listeners[0].UserID == i.Member.User.ID
+}
+
+// guildSettings loads a guild's settings, or returns nil if there is no
+// database to keep them in
+func guildSettings(bot BotInterface, guildID string) *storage.GuildSettings {
+	store := bot.Storage()
+	if store == nil || !store.Persistent() {
+		return nil
+	}
+
+	settings, err := store.GetGuildSettings(guildID)
+	if err != nil {
+		// Don't lock everyone out because the database is having a moment
+		fmt.Printf("[permissions] Failed to load guild settings: %v\n", err)
+		return nil
+	}
+	return settings
+}
+
+// voiceListeners returns the voice states of the people in a channel,
+// leaving out bots
+func voiceListeners(s *discordgo.Session, guildID, channelID string) []*discordgo.VoiceState {
+	guild, err := s.State.Guild(guildID)
+	if err != nil {
+		return nil
+	}
+
+	var listeners []*discordgo.VoiceState
+	for _, vs := range guild.VoiceStates {
+		if vs.ChannelID != channelID || vs.UserID == s.State.User.ID || isBotUser(s, guildID, vs) {
+			continue
+		}
+		listeners = append(listeners, vs)
+	}
+	return listeners
+}
+
+func isBotUser(s *discordgo.Session, guildID string, vs *discordgo.VoiceState) bool {
+	if vs.Member != nil && vs.Member.User != nil {
+		return vs.Member.User.Bot
+	}
+	if member, err := s.State.Member(guildID, vs.UserID); err == nil && member.User != nil {
+		return member.User.Bot
+	}
+	return false
+}
+
+func denyDJ(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embeds.Error("DJ Only", "You need the DJ role to do that")},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestNeedsDJ(t *testing.T) {
+	level := &discordgo.ApplicationCommandInteractionDataOption{
+		Name:  "level",
+		Type:  discordgo.ApplicationCommandOptionInteger,
+		Value: float64(80),
+	}
+	clear := &discordgo.ApplicationCommandInteractionDataOption{
+		Name: "clear",
+		Type: discordgo.ApplicationCommandOptionSubCommand,
+	}
+
+	tests := []struct {
+		name string
+		data discordgo.ApplicationCommandInteractionData
+		want bool
+	}{
+		{"viewing the volume", discordgo.ApplicationCommandInteractionData{Name: "volume"}, false},
+		{"setting the volume", discordgo.ApplicationCommandInteractionData{Name: "volume", Options: []*discordgo.ApplicationCommandInteractionDataOption{level}}, true},
+		{"stopping", discordgo.ApplicationCommandInteractionData{Name: "stop"}, true},
+		{"clearing the queue", discordgo.ApplicationCommandInteractionData{Name: "queue", Options: []*discordgo.ApplicationCommandInteractionDataOption{clear}}, true},
+		{"playing", discordgo.ApplicationCommandInteractionData{Name: "play"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := needsDJ(tt.data); got != tt.want {
+				t.Errorf("needsDJ(%s) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
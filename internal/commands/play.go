@@ -3,6 +3,7 @@ package commands
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/dickeyy/meow/internal/audio"
@@ -29,10 +30,35 @@ func handlePlay(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInt
 
 	fmt.Printf("[play] Query: %s\n", query)
 
-	voiceState, err := findUserVoiceState(s, i.GuildID, userID)
+	session := joinUserChannel(s, i, bot)
+	if session == nil {
+		return
+	}
+
+	tracks, err := findTracks(bot, query, userID)
+	if err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+
+	if len(tracks) == 0 {
+		respondError(s, i, "No tracks found")
+		return
+	}
+
+	fmt.Printf("[play] Found %d tracks\n", len(tracks))
+
+	enqueue(s, i, bot, session, tracks)
+}
+
+// joinUserChannel returns the guild's session, connected to the voice
+// channel of the user who ran the command. On failure it responds to the
+// deferred interaction and returns nil.
+func joinUserChannel(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInterface) *audio.Session {
+	voiceState, err := findUserVoiceState(s, i.GuildID, i.Member.User.ID)
 	if err != nil || voiceState == nil {
 		respondError(s, i, "You need to be in a voice channel to play music")
-		return
+		return nil
 	}
 
 	fmt.Printf("[play] User is in voice channel: %s\n", voiceState.ChannelID)
@@ -45,55 +71,69 @@ func handlePlay(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInt
 		vc, err = s.ChannelVoiceJoin(i.GuildID, voiceState.ChannelID, false, true)
 		if err != nil {
 			respondError(s, i, "Failed to join voice channel: "+err.Error())
-			return
+			return nil
 		}
 		session.SetVoiceConnection(vc)
 		session.SetChannelID(i.ChannelID)
 		fmt.Printf("[play] Joined voice channel\n")
+
+		// Encode at no more than the channel can carry
+		if channel, err := s.State.Channel(voiceState.ChannelID); err == nil {
+			session.SetChannelBitrate(channel.Bitrate)
+		}
 	}
 
-	var tracks []*audio.Track
+	return session
+}
 
+// findTracks looks up a Spotify URL, any other URL yt-dlp understands, or
+// failing that a YouTube search. The error is fit to show the user.
+func findTracks(bot BotInterface, query, userID string) ([]*audio.Track, error) {
 	if bot.Spotify() != nil && bot.Spotify().IsSpotifyURL(query) {
 		fmt.Printf("[play] Extracting Spotify URL...\n")
-		tracks, err = bot.Spotify().Extract(query, userID)
+		tracks, err := bot.Spotify().Extract(query, userID)
 		if err != nil {
 			fmt.Printf("[play] Spotify extract failed: %v\n", err)
-			respondError(s, i, "Failed to get Spotify tracks: "+err.Error())
-			return
+			return nil, fmt.Errorf("Failed to get Spotify tracks: %w", err)
 		}
-	} else if bot.YouTube().IsYouTubeURL(query) || strings.HasPrefix(query, "http") {
+		return tracks, nil
+	}
+
+	if bot.YouTube().IsYouTubeURL(query) || strings.HasPrefix(query, "http") {
 		fmt.Printf("[play] Extracting URL...\n")
-		tracks, err = bot.YouTube().Extract(query, userID)
+		tracks, err := bot.YouTube().Extract(query, userID)
 		if err != nil {
 			fmt.Printf("[play] Extract failed: %v\n", err)
-			respondError(s, i, "Failed to extract tracks: "+err.Error())
-			return
+			return nil, fmt.Errorf("Failed to extract tracks: %w", err)
 		}
-		// Try to get better artwork from iTunes for YouTube tracks
-		for _, track := range tracks {
-			fetchArtwork(bot, track)
-		}
-	} else {
-		fmt.Printf("[play] Searching YouTube for: %s\n", query)
-		track, err := bot.YouTube().Search(query, userID)
-		if err != nil {
-			fmt.Printf("[play] Search failed: %v\n", err)
-			respondError(s, i, "Search failed: "+err.Error())
-			return
+		// Try to get better artwork from iTunes for single YouTube tracks.
+		// Playlist entries get theirs when they are resolved.
+		if len(tracks) == 1 {
+			fetchArtwork(bot, tracks[0])
 		}
-		// Try to get better artwork from iTunes
-		fetchArtwork(bot, track)
-		tracks = []*audio.Track{track}
+		return tracks, nil
+	}
+
+	fmt.Printf("[play] Searching YouTube for: %s\n", query)
+	track, err := bot.YouTube().Search(query, userID)
+	if err != nil {
+		fmt.Printf("[play] Search failed: %v\n", err)
+		return nil, fmt.Errorf("Search failed: %w", err)
 	}
+	// Try to get better artwork from iTunes
+	fetchArtwork(bot, track)
+	return []*audio.Track{track}, nil
+}
 
+// enqueue adds tracks to the session's queue, starting playback if nothing
+// was queued, and responds to the deferred interaction
+func enqueue(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInterface, session *audio.Session, tracks []*audio.Track) {
+	tracks, note := applyQueueLimits(bot, session, tracks)
 	if len(tracks) == 0 {
-		respondError(s, i, "No tracks found")
+		respondError(s, i, note)
 		return
 	}
 
-	fmt.Printf("[play] Found %d tracks\n", len(tracks))
-
 	wasEmpty := session.Queue().IsEmpty()
 	session.Queue().Add(tracks...)
 
@@ -101,92 +141,34 @@ func handlePlay(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInt
 		firstTrack := session.Queue().Current()
 		fmt.Printf("[play] First track: %s\n", firstTrack.Title)
 
-		// Store original thumbnail (from Spotify) before YouTube search
-		originalThumbnail := firstTrack.Thumbnail
-
-		if firstTrack.Source == audio.SourceSpotify {
-			searchQuery := bot.Spotify().GetSearchQuery(firstTrack)
-			fmt.Printf("[play] Searching YouTube for Spotify track: %s\n", searchQuery)
-			ytTrack, err := bot.YouTube().Search(searchQuery, userID)
-			if err != nil {
-				fmt.Printf("[play] YouTube search for Spotify track failed: %v\n", err)
-				respondError(s, i, "Failed to find track on YouTube: "+err.Error())
-				return
-			}
-			firstTrack.StreamURL = ytTrack.StreamURL
-			// Keep the original Spotify thumbnail, don't use YouTube's
-			if firstTrack.Duration == 0 {
-				firstTrack.Duration = ytTrack.Duration
-			}
-		}
-
-		// Restore original thumbnail if it was set (Spotify)
-		if originalThumbnail != "" {
-			firstTrack.Thumbnail = originalThumbnail
+		if err := startPlayback(s, bot, session, 0); err != nil {
+			fmt.Printf("[play] Failed to resolve first track: %v\n", err)
+			respondError(s, i, "Failed to get stream URL: "+err.Error())
+			return
 		}
 
-		if firstTrack.StreamURL == "" {
-			fmt.Printf("[play] Getting stream URL...\n")
-			streamURL, err := bot.YouTube().GetStreamURL(firstTrack)
-			if err != nil {
-				fmt.Printf("[play] Failed to get stream URL: %v\n", err)
-				respondError(s, i, "Failed to get stream URL: "+err.Error())
-				return
-			}
-			firstTrack.StreamURL = streamURL
-		}
-
-		fmt.Printf("[play] Got stream URL, starting playback...\n")
-
-		session.OnTrackChange = func(track *audio.Track) {
-			fmt.Printf("[player] Track changed to: %s\n", track.Title)
-			
-			// Store original thumbnail before YouTube search
-			originalThumb := track.Thumbnail
-			
-			if track.Source == audio.SourceSpotify && track.StreamURL == "" {
-				searchQuery := bot.Spotify().GetSearchQuery(track)
-				ytTrack, err := bot.YouTube().Search(searchQuery, userID)
-				if err == nil {
-					track.StreamURL = ytTrack.StreamURL
-					// Don't overwrite Spotify thumbnail
-					if track.Duration == 0 {
-						track.Duration = ytTrack.Duration
-					}
-				}
-				// Restore Spotify thumbnail
-				if originalThumb != "" {
-					track.Thumbnail = originalThumb
-				}
-			} else if track.StreamURL == "" {
-				streamURL, _ := bot.YouTube().GetStreamURL(track)
-				track.StreamURL = streamURL
-			}
-
-			// For non-Spotify tracks without artwork, try iTunes
-			if track.Thumbnail == "" || track.Source != audio.SourceSpotify {
-				fetchArtwork(bot, track)
-			}
-
-			sendNowPlayingEmbed(s, session.ChannelID(), track, session)
+		if note != "" {
+			s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+				Embeds: &[]*discordgo.MessageEmbed{embeds.Info("Queue Limits", note)},
+			})
+			return
 		}
 
-		player := audio.NewPlayer()
-		go func() {
-			if err := player.Play(session, s); err != nil {
-				fmt.Printf("[player] Playback error: %v\n", err)
-			}
-		}()
-
 		// Delete the deferred response since we'll send the Now Playing embed from OnTrackChange
 		s.InteractionResponseDelete(i.Interaction)
 	} else {
+		// Newly added tracks may now be next up
+		session.PrefetchUpcoming()
+
 		var content string
 		if len(tracks) == 1 {
 			content = fmt.Sprintf("Added to queue: **%s**", tracks[0].Title)
 		} else {
 			content = fmt.Sprintf("Added **%d** tracks to queue", len(tracks))
 		}
+		if note != "" {
+			content += "\n" + note
+		}
 
 		embed := embeds.Success("Queue Updated", content)
 		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
@@ -195,6 +177,142 @@ func handlePlay(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInt
 	}
 }
 
+// startPlayback resolves the session's current track, hooks up the now
+// playing messages and autoplay, and starts the player at offset
+func startPlayback(s *discordgo.Session, bot BotInterface, session *audio.Session, offset time.Duration) error {
+	if session.LoudnessMeter() == nil {
+		session.SetLoudnessMeter(audio.NewLoudnessMeter(func(track *audio.Track, lufs, truePeak float64) {
+			cacheLoudness(bot, track, lufs, truePeak)
+		}))
+	}
+
+	session.SetPrefetcher(audio.NewPrefetcher(func(track *audio.Track) error {
+		if err := resolveTrack(bot, track); err != nil {
+			return err
+		}
+		if session.Normalize() {
+			loadLoudness(bot, session, track)
+		}
+		return nil
+	}, bot.Config().PrefetchDepth))
+
+	if err := session.Prefetcher().Resolve(session.Queue().Current()); err != nil {
+		return err
+	}
+
+	fmt.Printf("[play] Got stream URL, starting playback...\n")
+
+	session.OnTrackChange = func(track *audio.Track) {
+		fmt.Printf("[player] Track changed to: %s\n", track.Title)
+		sendNowPlayingEmbed(s, announceChannel(bot, session), track, session)
+	}
+
+	session.OnQueueEnd = func(history []*audio.Track) []*audio.Track {
+		return relatedTracks(bot, history)
+	}
+
+	session.OnTrackError = func(track *audio.Track, err error) {
+		embed := embeds.Error("Playback Error", fmt.Sprintf("Lost the stream for **%s** and couldn't get it back, skipping", track.Title))
+		s.ChannelMessageSendEmbed(announceChannel(bot, session), embed)
+	}
+
+	if store := bot.Storage(); store != nil && store.Persistent() {
+		session.OnTrackPlayed = func(play audio.TrackPlay) {
+			recordPlay(store, session.GuildID(), play)
+		}
+	}
+
+	player := audio.NewPlayer()
+	go func() {
+		if err := player.PlayFrom(session, s, offset); err != nil {
+			fmt.Printf("[player] Playback error: %v\n", err)
+		}
+	}()
+
+	return nil
+}
+
+// resolveTrack fills in the stream URL, duration and artwork for a queued
+// track. It runs ahead of time from the session's prefetcher and again if a
+// track's stream URL is about to expire.
+func resolveTrack(bot BotInterface, track *audio.Track) error {
+	// Tracks from playlists are queued without a stream URL or artwork
+	firstResolve := track.StreamURL() == ""
+
+	// Reuse a stream URL cached by an earlier play of the same track
+	if store := bot.Storage(); store != nil && track.ID != "" && track.NeedsResolve() {
+		// Skip the cache if it's holding the URL that just stopped working
+		if cached, err := store.GetCachedStreamURL(track.ID); err == nil && cached != "" && cached != track.StreamURL() {
+			track.SetStreamURL(cached)
+		}
+	}
+
+	if track.NeedsResolve() {
+		if track.Source == audio.SourceSpotify {
+			searchQuery := bot.Spotify().GetSearchQuery(track)
+			fmt.Printf("[resolve] Searching YouTube for Spotify track: %s\n", searchQuery)
+			ytTrack, err := bot.YouTube().Search(searchQuery, track.RequestedBy)
+			if err != nil {
+				return fmt.Errorf("failed to find track on YouTube: %w", err)
+			}
+
+			// Keep the original Spotify thumbnail, don't use YouTube's
+			track.SetStreamURL(ytTrack.StreamURL())
+			if track.Duration == 0 {
+				track.Duration = ytTrack.Duration
+			}
+		} else {
+			streamURL, err := bot.YouTube().GetStreamURL(track)
+			if err != nil {
+				return err
+			}
+			track.SetStreamURL(streamURL)
+		}
+
+		if store := bot.Storage(); store != nil && track.ID != "" {
+			if err := store.CacheStreamURL(track.ID, track.StreamURL()); err != nil {
+				fmt.Printf("[resolve] Failed to cache stream URL: %v\n", err)
+			}
+		}
+	}
+
+	if firstResolve {
+		fetchArtwork(bot, track)
+	}
+
+	return nil
+}
+
+// loadLoudness fills in a track's measured loudness from the cache, or
+// queues it on the session's loudness meter. Until the measurement is done
+// the player falls back to ffmpeg's single-pass loudnorm.
+func loadLoudness(bot BotInterface, session *audio.Session, track *audio.Track) {
+	if track.Loudness() != 0 || track.ID == "" {
+		return
+	}
+
+	if store := bot.Storage(); store != nil {
+		if lufs, truePeak, err := store.GetCachedLoudness(track.ID); err == nil && lufs != 0 {
+			track.SetLoudness(lufs, truePeak)
+			return
+		}
+	}
+
+	if meter := session.LoudnessMeter(); meter != nil {
+		meter.Measure(track)
+	}
+}
+
+func cacheLoudness(bot BotInterface, track *audio.Track, lufs, truePeak float64) {
+	store := bot.Storage()
+	if store == nil || track.ID == "" {
+		return
+	}
+	if err := store.CacheLoudness(track.ID, lufs, truePeak); err != nil {
+		fmt.Printf("[loudness] Failed to cache loudness: %v\n", err)
+	}
+}
+
 func fetchArtwork(bot BotInterface, track *audio.Track) {
 	if bot.Artwork() == nil {
 		return
@@ -240,10 +358,15 @@ func respondError(s *discordgo.Session, i *discordgo.InteractionCreate, message
 
 func sendNowPlayingEmbed(s *discordgo.Session, channelID string, track *audio.Track, session *audio.Session) {
 	embed := embeds.NowPlaying(track, session)
-	components := embeds.PlayerButtons(session.IsPaused())
+	components := embeds.PlayerButtons(session)
 
-	s.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+	msg, err := s.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
 		Embeds:     []*discordgo.MessageEmbed{embed},
 		Components: components,
 	})
+	if err != nil {
+		fmt.Printf("[player] Failed to send now playing message: %v\n", err)
+		return
+	}
+	session.SetNowPlayingMessage(channelID, msg.ID)
 }
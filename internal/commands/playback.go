@@ -1,7 +1,10 @@
 package commands
 
 import (
+	"fmt"
+
 	"github.com/bwmarrin/discordgo"
+	"github.com/dickeyy/meow/internal/audio"
 	"github.com/dickeyy/meow/internal/embeds"
 )
 
@@ -44,7 +47,32 @@ func handleSkip(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInt
 		return
 	}
 
-	if !session.Queue().HasNext() {
+	switch decision, percent := decideSkip(s, i, bot); decision {
+	case skipDenied:
+		denyDJ(s, i)
+		return
+	case skipVote:
+		result, err := castSkipVote(s, i, session, percent)
+		if err != nil {
+			respond(s, i, embeds.Error("Error", err.Error()))
+			return
+		}
+		if !result.passed() {
+			title := session.Queue().Current().Title
+			if result.added {
+				respond(s, i, embeds.Info("Vote to Skip", fmt.Sprintf("<@%s> voted to skip **%s** (%d/%d)", i.Member.User.ID, title, result.votes, result.needed)))
+				refreshNowPlaying(s, session)
+			} else {
+				respond(s, i, embeds.Info("Vote to Skip", fmt.Sprintf("You already voted to skip **%s** (%d/%d)", title, result.votes, result.needed)))
+			}
+			return
+		}
+		session.ClearSkipVotes()
+	}
+
+	// A looping queue always has something to skip to, and autoplay will
+	// find something
+	if !session.Queue().HasNext() && session.LoopMode() != audio.LoopQueue && !session.Autoplay() {
 		session.Stop()
 		respond(s, i, embeds.Info("Queue Empty", "No more tracks in queue"))
 		return
@@ -129,7 +157,8 @@ func handleNowPlaying(s *discordgo.Session, i *discordgo.InteractionCreate, bot
 	}
 
 	embed := embeds.NowPlaying(track, session)
-	components := embeds.PlayerButtons(session.IsPaused())
+	embed.Fields = append(embed.Fields, embeds.StreamHealth(session.StreamStats()))
+	components := embeds.PlayerButtons(session)
 
 	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
@@ -161,9 +190,7 @@ func handlePlayerPause(s *discordgo.Session, i *discordgo.InteractionCreate, bot
 	
 	track := session.Queue().Current()
 	if track != nil {
-		embed := embeds.NowPlaying(track, session)
-		components := embeds.PlayerButtons(true)
-		updateMessage(s, i, embed, components)
+		updateNowPlaying(s, i, track, session)
 	}
 }
 
@@ -178,9 +205,7 @@ func handlePlayerResume(s *discordgo.Session, i *discordgo.InteractionCreate, bo
 	
 	track := session.Queue().Current()
 	if track != nil {
-		embed := embeds.NowPlaying(track, session)
-		components := embeds.PlayerButtons(false)
-		updateMessage(s, i, embed, components)
+		updateNowPlaying(s, i, track, session)
 	}
 }
 
@@ -191,6 +216,32 @@ func handlePlayerSkip(s *discordgo.Session, i *discordgo.InteractionCreate, bot
 		return
 	}
 
+	switch decision, percent := decideSkip(s, i, bot); decision {
+	case skipDenied:
+		denyDJ(s, i)
+		return
+	case skipVote:
+		result, err := castSkipVote(s, i, session, percent)
+		if err != nil {
+			respondComponent(s, i, embeds.Error("Error", err.Error()))
+			return
+		}
+		if !result.passed() {
+			if !result.added {
+				respondComponent(s, i, embeds.Info("Vote to Skip", fmt.Sprintf("You already voted (%d/%d)", result.votes, result.needed)))
+				return
+			}
+			// Show the new tally on the message the button is on
+			if track := session.Queue().Current(); track != nil {
+				updateNowPlaying(s, i, track, session)
+			} else {
+				acknowledgeComponent(s, i)
+			}
+			return
+		}
+		session.ClearSkipVotes()
+	}
+
 	session.Skip()
 	acknowledgeComponent(s, i)
 }
@@ -278,3 +329,8 @@ func updateMessage(s *discordgo.Session, i *discordgo.InteractionCreate, embed *
 	})
 }
 
+func updateNowPlaying(s *discordgo.Session, i *discordgo.InteractionCreate, track *audio.Track, session *audio.Session) {
+	embed := embeds.NowPlaying(track, session)
+	components := embeds.PlayerButtons(session)
+	updateMessage(s, i, embed, components)
+}
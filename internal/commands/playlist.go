@@ -0,0 +1,445 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dickeyy/meow/internal/embeds"
+	"github.com/dickeyy/meow/internal/storage"
+)
+
+const (
+	maxPlaylistNameLen = 32
+	maxPlaylistTracks  = 500
+
+	// How many tracks /playlist show lists before summarising the rest
+	playlistShowLimit = 20
+)
+
+func handlePlaylist(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInterface) {
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		respond(s, i, embeds.Error("Error", "Please specify a subcommand"))
+		return
+	}
+
+	store := bot.Storage()
+	if store == nil || !store.Persistent() {
+		respond(s, i, embeds.Error("Error", "Playlists require a database"))
+		return
+	}
+
+	subCmd := options[0]
+
+	switch subCmd.Name {
+	case "create":
+		handlePlaylistCreate(s, i, store, subCmd.Options)
+	case "add":
+		handlePlaylistAdd(s, i, bot, store, subCmd.Options)
+	case "remove":
+		handlePlaylistRemove(s, i, store, subCmd.Options)
+	case "list":
+		handlePlaylistList(s, i, store)
+	case "show":
+		handlePlaylistShow(s, i, store, subCmd.Options)
+	case "play":
+		handlePlaylistPlay(s, i, bot, store, subCmd.Options)
+	case "delete":
+		handlePlaylistDelete(s, i, store, subCmd.Options)
+	case "save-queue":
+		handlePlaylistSaveQueue(s, i, bot, store, subCmd.Options)
+	}
+}
+
+func handlePlaylistCreate(s *discordgo.Session, i *discordgo.InteractionCreate, store *storage.Storage, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	playlist, ok := newPlaylist(s, i, options)
+	if !ok {
+		return
+	}
+
+	if err := createPlaylist(store, playlist); err != nil {
+		respond(s, i, embeds.Error("Error", err.Error()))
+		return
+	}
+
+	respond(s, i, embeds.Success("Playlist Created", fmt.Sprintf("Created %s playlist **%s**", playlistKind(playlist), playlist.Name)))
+}
+
+func handlePlaylistAdd(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInterface, store *storage.Storage, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	// Looking the tracks up can take a while
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	})
+	if err != nil {
+		fmt.Printf("[playlist] Failed to defer response: %v\n", err)
+		return
+	}
+
+	playlist, err := store.GetPlaylist(i.Member.User.ID, i.GuildID, playlistNameOption(options), playlistScopeOption(options))
+	if err != nil {
+		respondError(s, i, "Failed to load playlist")
+		return
+	}
+	if playlist == nil {
+		respondError(s, i, fmt.Sprintf("No playlist named **%s**", playlistNameOption(options)))
+		return
+	}
+	if !canEditPlaylist(i, playlist) {
+		respondError(s, i, "Only the owner or someone with Manage Server can change this playlist")
+		return
+	}
+
+	var query string
+	for _, opt := range options {
+		if opt.Name == "query" {
+			query = opt.StringValue()
+		}
+	}
+
+	tracks, err := findTracks(bot, query, i.Member.User.ID)
+	if err != nil {
+		respondError(s, i, err.Error())
+		return
+	}
+	if len(tracks) == 0 {
+		respondError(s, i, "No tracks found")
+		return
+	}
+
+	if playlist.TrackCount+len(tracks) > maxPlaylistTracks {
+		respondError(s, i, fmt.Sprintf("Playlists can hold at most %d tracks", maxPlaylistTracks))
+		return
+	}
+
+	if err := store.AddPlaylistTracks(playlist.ID, saveTracks(tracks)); err != nil {
+		fmt.Printf("[playlist] Failed to add tracks: %v\n", err)
+		respondError(s, i, "Failed to add tracks")
+		return
+	}
+
+	var content string
+	if len(tracks) == 1 {
+		content = fmt.Sprintf("Added **%s** to **%s**", tracks[0].Title, playlist.Name)
+	} else {
+		content = fmt.Sprintf("Added **%d** tracks to **%s**", len(tracks), playlist.Name)
+	}
+
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embeds.Success("Playlist Updated", content)},
+	})
+}
+
+func handlePlaylistRemove(s *discordgo.Session, i *discordgo.InteractionCreate, store *storage.Storage, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	playlist, ok := loadPlaylist(s, i, store, options)
+	if !ok {
+		return
+	}
+	if !canEditPlaylist(i, playlist) {
+		respond(s, i, embeds.Error("Error", "Only the owner or someone with Manage Server can change this playlist"))
+		return
+	}
+
+	position := -1
+	for _, opt := range options {
+		if opt.Name == "position" {
+			position = int(opt.IntValue()) - 1
+		}
+	}
+
+	if position < 0 || position >= len(playlist.Tracks) {
+		respond(s, i, embeds.Error("Error", "Invalid position. Make sure the position is within the playlist."))
+		return
+	}
+
+	removed, err := store.RemovePlaylistTrack(playlist.ID, position)
+	if err != nil || !removed {
+		fmt.Printf("[playlist] Failed to remove track: %v\n", err)
+		respond(s, i, embeds.Error("Error", "Failed to remove track"))
+		return
+	}
+
+	respond(s, i, embeds.Success("Playlist Updated", fmt.Sprintf("Removed **%s** from **%s**", playlist.Tracks[position].Title, playlist.Name)))
+}
+
+func handlePlaylistList(s *discordgo.Session, i *discordgo.InteractionCreate, store *storage.Storage) {
+	playlists, err := store.ListPlaylists(i.Member.User.ID, i.GuildID)
+	if err != nil {
+		fmt.Printf("[playlist] Failed to list playlists: %v\n", err)
+		respond(s, i, embeds.Error("Error", "Failed to load playlists"))
+		return
+	}
+
+	if len(playlists) == 0 {
+		respond(s, i, embeds.Info("Playlists", "No playlists yet. Create one with `/playlist create`."))
+		return
+	}
+
+	var personal, server []string
+	for _, p := range playlists {
+		line := fmt.Sprintf("**%s** - %d tracks", p.Name, p.TrackCount)
+		if p.IsServer() {
+			server = append(server, line)
+		} else {
+			personal = append(personal, line)
+		}
+	}
+
+	var description string
+	if len(personal) > 0 {
+		description += "**Yours**\n" + strings.Join(personal, "\n") + "\n\n"
+	}
+	if len(server) > 0 {
+		description += "**This server's**\n" + strings.Join(server, "\n")
+	}
+
+	respond(s, i, embeds.Info("Playlists", strings.TrimSpace(description)))
+}
+
+func handlePlaylistShow(s *discordgo.Session, i *discordgo.InteractionCreate, store *storage.Storage, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	playlist, ok := loadPlaylist(s, i, store, options)
+	if !ok {
+		return
+	}
+
+	if len(playlist.Tracks) == 0 {
+		respond(s, i, embeds.Info(playlist.Name, "This playlist is empty. Add tracks with `/playlist add`."))
+		return
+	}
+
+	var lines []string
+	var total time.Duration
+	for n, track := range playlist.Tracks {
+		total += track.Duration
+		if n < playlistShowLimit {
+			lines = append(lines, fmt.Sprintf("`%d.` %s - %s", n+1, track.Title, embeds.FormatDuration(track.Duration)))
+		}
+	}
+	if len(playlist.Tracks) > playlistShowLimit {
+		lines = append(lines, fmt.Sprintf("...and %d more", len(playlist.Tracks)-playlistShowLimit))
+	}
+
+	description := fmt.Sprintf("A %s playlist by <@%s>, %d tracks (%s)\n\n%s",
+		playlistKind(playlist),
+		playlist.OwnerID,
+		len(playlist.Tracks),
+		embeds.FormatDuration(total),
+		strings.Join(lines, "\n"),
+	)
+
+	respond(s, i, embeds.Info(playlist.Name, description))
+}
+
+func handlePlaylistPlay(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInterface, store *storage.Storage, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	})
+	if err != nil {
+		fmt.Printf("[playlist] Failed to defer response: %v\n", err)
+		return
+	}
+
+	name := playlistNameOption(options)
+	playlist, err := store.GetPlaylist(i.Member.User.ID, i.GuildID, name, playlistScopeOption(options))
+	if err != nil {
+		respondError(s, i, "Failed to load playlist")
+		return
+	}
+	if playlist == nil {
+		respondError(s, i, fmt.Sprintf("No playlist named **%s**", name))
+		return
+	}
+	if len(playlist.Tracks) == 0 {
+		respondError(s, i, "This playlist is empty")
+		return
+	}
+
+	session := joinUserChannel(s, i, bot)
+	if session == nil {
+		return
+	}
+
+	tracks := restoreTracks(playlist.Tracks)
+	for _, track := range tracks {
+		track.RequestedBy = i.Member.User.ID
+	}
+
+	fmt.Printf("[playlist] Playing %s with %d tracks\n", playlist.Name, len(tracks))
+
+	enqueue(s, i, bot, session, tracks)
+}
+
+func handlePlaylistDelete(s *discordgo.Session, i *discordgo.InteractionCreate, store *storage.Storage, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	playlist, ok := loadPlaylist(s, i, store, options)
+	if !ok {
+		return
+	}
+	if !canEditPlaylist(i, playlist) {
+		respond(s, i, embeds.Error("Error", "Only the owner or someone with Manage Server can delete this playlist"))
+		return
+	}
+
+	if err := store.DeletePlaylist(playlist.ID); err != nil {
+		fmt.Printf("[playlist] Failed to delete playlist: %v\n", err)
+		respond(s, i, embeds.Error("Error", "Failed to delete playlist"))
+		return
+	}
+
+	respond(s, i, embeds.Success("Playlist Deleted", fmt.Sprintf("Deleted **%s**", playlist.Name)))
+}
+
+// handlePlaylistSaveQueue saves the current track and everything queued
+// after it, creating the playlist or replacing its tracks
+func handlePlaylistSaveQueue(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInterface, store *storage.Storage, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	session := bot.GetSession(i.GuildID)
+	if session == nil || session.Queue().IsEmpty() {
+		respond(s, i, embeds.Error("Error", "The queue is empty"))
+		return
+	}
+
+	tracks := session.Queue().All()
+	if len(tracks) > maxPlaylistTracks {
+		respond(s, i, embeds.Error("Error", fmt.Sprintf("Playlists can hold at most %d tracks", maxPlaylistTracks)))
+		return
+	}
+
+	playlist, ok := newPlaylist(s, i, options)
+	if !ok {
+		return
+	}
+
+	// Only overwrite a playlist of the kind that was asked for, not a
+	// personal one that happens to share the server playlist's name
+	scope := storage.PersonalPlaylist
+	if playlist.IsServer() {
+		scope = storage.ServerPlaylist
+	}
+	existing, err := store.GetPlaylist(i.Member.User.ID, i.GuildID, playlist.Name, scope)
+	if err != nil {
+		respond(s, i, embeds.Error("Error", "Failed to load playlist"))
+		return
+	}
+
+	if existing != nil {
+		if !canEditPlaylist(i, existing) {
+			respond(s, i, embeds.Error("Error", "Only the owner or someone with Manage Server can change this playlist"))
+			return
+		}
+		playlist = existing
+	} else if err := createPlaylist(store, playlist); err != nil {
+		respond(s, i, embeds.Error("Error", err.Error()))
+		return
+	}
+
+	if err := store.ReplacePlaylistTracks(playlist.ID, saveTracks(tracks)); err != nil {
+		fmt.Printf("[playlist] Failed to save queue: %v\n", err)
+		respond(s, i, embeds.Error("Error", "Failed to save queue"))
+		return
+	}
+
+	respond(s, i, embeds.Success("Playlist Saved", fmt.Sprintf("Saved **%d** tracks to %s playlist **%s**", len(tracks), playlistKind(playlist), playlist.Name)))
+}
+
+// newPlaylist builds an unsaved playlist from the name and server options,
+// responding with an error if they aren't valid
+func newPlaylist(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) (*storage.Playlist, bool) {
+	name := playlistNameOption(options)
+	if name == "" || len(name) > maxPlaylistNameLen {
+		respond(s, i, embeds.Error("Error", fmt.Sprintf("Playlist names must be 1-%d characters", maxPlaylistNameLen)))
+		return nil, false
+	}
+
+	playlist := &storage.Playlist{
+		Name:    name,
+		OwnerID: i.Member.User.ID,
+	}
+
+	for _, opt := range options {
+		if opt.Name == "server" && opt.BoolValue() {
+			if !canManageServer(i) {
+				respond(s, i, embeds.Error("Error", "You need Manage Server to create server playlists"))
+				return nil, false
+			}
+			playlist.GuildID = i.GuildID
+		}
+	}
+
+	return playlist, true
+}
+
+func createPlaylist(store *storage.Storage, playlist *storage.Playlist) error {
+	err := store.CreatePlaylist(playlist)
+	if errors.Is(err, storage.ErrPlaylistExists) {
+		return fmt.Errorf("There is already a %s playlist named **%s**", playlistKind(playlist), playlist.Name)
+	}
+	if err != nil {
+		fmt.Printf("[playlist] Failed to create playlist: %v\n", err)
+		return fmt.Errorf("Failed to create playlist")
+	}
+	return nil
+}
+
+// loadPlaylist finds the playlist named in the options, responding with an
+// error if there isn't one
+func loadPlaylist(s *discordgo.Session, i *discordgo.InteractionCreate, store *storage.Storage, options []*discordgo.ApplicationCommandInteractionDataOption) (*storage.Playlist, bool) {
+	name := playlistNameOption(options)
+
+	playlist, err := store.GetPlaylist(i.Member.User.ID, i.GuildID, name, playlistScopeOption(options))
+	if err != nil {
+		fmt.Printf("[playlist] Failed to load playlist: %v\n", err)
+		respond(s, i, embeds.Error("Error", "Failed to load playlist"))
+		return nil, false
+	}
+	if playlist == nil {
+		respond(s, i, embeds.Error("Error", fmt.Sprintf("No playlist named **%s**", name)))
+		return nil, false
+	}
+
+	return playlist, true
+}
+
+// canEditPlaylist reports whether the user may change a playlist. Anyone
+// with Manage Server can look after the server's playlists.
+func canEditPlaylist(i *discordgo.InteractionCreate, playlist *storage.Playlist) bool {
+	if playlist.OwnerID == i.Member.User.ID {
+		return true
+	}
+	return playlist.IsServer() && canManageServer(i)
+}
+
+func canManageServer(i *discordgo.InteractionCreate) bool {
+	return i.Member.Permissions&discordgo.PermissionManageServer != 0
+}
+
+func playlistKind(playlist *storage.Playlist) string {
+	if playlist.IsServer() {
+		return "server"
+	}
+	return "personal"
+}
+
+// playlistScopeOption reads the server option, which picks between a
+// personal and a server playlist of the same name. Left out, the user's own
+// playlist wins.
+func playlistScopeOption(options []*discordgo.ApplicationCommandInteractionDataOption) storage.PlaylistScope {
+	for _, opt := range options {
+		if opt.Name == "server" {
+			if opt.BoolValue() {
+				return storage.ServerPlaylist
+			}
+			return storage.PersonalPlaylist
+		}
+	}
+	return storage.AnyPlaylist
+}
+
+func playlistNameOption(options []*discordgo.ApplicationCommandInteractionDataOption) string {
+	for _, opt := range options {
+		if opt.Name == "name" {
+			return strings.ToLower(strings.TrimSpace(opt.StringValue()))
+		}
+	}
+	return ""
+}
@@ -27,6 +27,10 @@ func handleQueue(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotIn
 		handleQueueRemove(s, i, bot, session, subCmd.Options)
 	case "clear":
 		handleQueueClear(s, i, bot, session)
+	case "export":
+		handleQueueExport(s, i, bot, subCmd.Options)
+	case "import":
+		handleQueueImport(s, i, bot, subCmd.Options)
 	}
 }
 
@@ -0,0 +1,215 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dickeyy/meow/internal/audio"
+	"github.com/dickeyy/meow/internal/embeds"
+)
+
+const (
+	// Queue files bigger than this aren't downloaded
+	maxImportSize = 1 << 20
+
+	// Entries past this many are ignored, since each one without a URL
+	// means a search
+	maxImportTracks = 200
+)
+
+var importClient = &http.Client{Timeout: 30 * time.Second}
+
+func handleQueueExport(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInterface, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	session := bot.GetSession(i.GuildID)
+	if session == nil || (session.Queue().IsEmpty() && len(session.Queue().History()) == 0) {
+		respond(s, i, embeds.Error("Error", "The queue is empty"))
+		return
+	}
+
+	format := audio.FormatM3U8
+	for _, opt := range options {
+		if opt.Name == "format" {
+			if f, ok := audio.ParseQueueFormat(opt.StringValue()); ok {
+				format = f
+			}
+		}
+	}
+
+	tracks := session.Queue().All()
+	history := session.Queue().History()
+
+	data, err := audio.ExportQueue(format, tracks, history)
+	if err != nil {
+		fmt.Printf("[queue] Failed to export queue: %v\n", err)
+		respond(s, i, embeds.Error("Error", "Failed to export the queue"))
+		return
+	}
+
+	embed := embeds.Success("Queue Exported", fmt.Sprintf("Exported **%d** queued and **%d** played tracks as %s", len(tracks), len(history), format))
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Files: []*discordgo.File{{
+				Name:        "queue." + string(format),
+				ContentType: format.ContentType(),
+				Reader:      bytes.NewReader(data),
+			}},
+		},
+	})
+}
+
+func handleQueueImport(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInterface, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	// Downloading and looking up the entries can take a while
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	})
+	if err != nil {
+		fmt.Printf("[queue] Failed to defer response: %v\n", err)
+		return
+	}
+
+	var attachment *discordgo.MessageAttachment
+	for _, opt := range options {
+		if opt.Name == "file" {
+			if id, ok := opt.Value.(string); ok {
+				attachment = i.ApplicationCommandData().Resolved.Attachments[id]
+			}
+		}
+	}
+	if attachment == nil {
+		respondError(s, i, "Please attach an M3U, XSPF or JSON file")
+		return
+	}
+	if attachment.Size > maxImportSize {
+		respondError(s, i, fmt.Sprintf("Queue files can be at most %d KB", maxImportSize>>10))
+		return
+	}
+
+	data, err := downloadAttachment(attachment.URL)
+	if err != nil {
+		fmt.Printf("[queue] Failed to download %s: %v\n", attachment.Filename, err)
+		respondError(s, i, "Failed to download the file")
+		return
+	}
+
+	entries, err := audio.ImportQueue(attachment.Filename, data)
+	if err != nil {
+		respondError(s, i, "Couldn't read the file: "+err.Error())
+		return
+	}
+	if len(entries) == 0 {
+		respondError(s, i, "No tracks found in the file")
+		return
+	}
+
+	skipped := 0
+	if len(entries) > maxImportTracks {
+		skipped = len(entries) - maxImportTracks
+		entries = entries[:maxImportTracks]
+	}
+
+	session := joinUserChannel(s, i, bot)
+	if session == nil {
+		return
+	}
+
+	fmt.Printf("[queue] Importing %d entries from %s\n", len(entries), attachment.Filename)
+
+	userID := i.Member.User.ID
+	var tracks []*audio.Track
+	for _, entry := range entries {
+		found, err := lookupImported(bot, entry, userID)
+		if err != nil {
+			fmt.Printf("[queue] Skipping %q: %v\n", entry.Title+entry.URL, err)
+			skipped++
+			continue
+		}
+		tracks = append(tracks, found...)
+	}
+
+	if len(tracks) == 0 {
+		respondError(s, i, "None of the tracks in the file could be found")
+		return
+	}
+	if skipped > 0 {
+		fmt.Printf("[queue] Skipped %d entries from %s\n", skipped, attachment.Filename)
+	}
+
+	enqueue(s, i, bot, session, tracks)
+}
+
+// lookupImported turns an imported entry into tracks. Entries with both a
+// title and a URL are queued as they are and resolved when they come up,
+// a bare URL is extracted like /play would, and a bare title is searched
+// for.
+func lookupImported(bot BotInterface, entry *audio.Track, userID string) ([]*audio.Track, error) {
+	switch {
+	case entry.URL != "" && entry.Title != "":
+		entry.RequestedBy = userID
+		if entry.Source == "" {
+			entry.Source = guessSource(bot, entry.URL)
+		}
+		return []*audio.Track{entry}, nil
+
+	case entry.URL != "":
+		return findTracks(bot, entry.URL, userID)
+
+	default:
+		query := entry.Title
+		if entry.Artist != "" {
+			query = entry.Artist + " - " + entry.Title
+		}
+		track, err := bot.YouTube().Search(query, userID)
+		if err != nil {
+			return nil, err
+		}
+		fetchArtwork(bot, track)
+		return []*audio.Track{track}, nil
+	}
+}
+
+// guessSource labels an imported URL the way /play would have. Anything
+// that isn't Spotify is streamed through yt-dlp.
+func guessSource(bot BotInterface, url string) audio.TrackSource {
+	if bot.Spotify() != nil && bot.Spotify().IsSpotifyURL(url) {
+		return audio.SourceSpotify
+	}
+	return audio.SourceYouTube
+}
+
+func downloadAttachment(url string) ([]byte, error) {
+	resp, err := importClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImportSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxImportSize {
+		return nil, fmt.Errorf("file is larger than %d bytes", maxImportSize)
+	}
+	return data, nil
+}
+
+func queueFormatChoices() []*discordgo.ApplicationCommandOptionChoice {
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, len(audio.QueueFormats))
+	for i, f := range audio.QueueFormats {
+		choices[i] = &discordgo.ApplicationCommandOptionChoice{
+			Name:  f.String(),
+			Value: string(f),
+		}
+	}
+	return choices
+}
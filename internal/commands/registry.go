@@ -95,6 +95,20 @@ func (r *Registry) registerCommands() {
 		Description: "Stop playback and clear the queue",
 	}, handleStop)
 
+	// Seek command
+	r.addCommand(&discordgo.ApplicationCommand{
+		Name:        "seek",
+		Description: "Jump to a position in the current track",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "position",
+				Description: "Timestamp (1:23, 83, 1m23s) or offset (+30, -15s)",
+				Required:    true,
+			},
+		},
+	}, handleSeek)
+
 	// Volume command
 	r.addCommand(&discordgo.ApplicationCommand{
 		Name:        "volume",
@@ -161,6 +175,32 @@ func (r *Registry) registerCommands() {
 				Name:        "clear",
 				Description: "Clear all tracks from the queue",
 			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "export",
+				Description: "Download the queue and history as a playlist file",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "format",
+						Description: "File format (defaults to M3U8)",
+						Choices:     queueFormatChoices(),
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "import",
+				Description: "Add the tracks from an M3U, XSPF or JSON file to the queue",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionAttachment,
+						Name:        "file",
+						Description: "Playlist file to import",
+						Required:    true,
+					},
+				},
+			},
 		},
 	}, handleQueue)
 
@@ -170,6 +210,551 @@ func (r *Registry) registerCommands() {
 		Description: "Shuffle the queue",
 	}, handleShuffle)
 
+	// Loop command
+	r.addCommand(&discordgo.ApplicationCommand{
+		Name:        "loop",
+		Description: "Loop the current track or the whole queue",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "mode",
+				Description: "What to loop",
+				Required:    true,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "Track", Value: "track"},
+					{Name: "Queue", Value: "queue"},
+					{Name: "Off", Value: "off"},
+				},
+			},
+		},
+	}, handleLoop)
+
+	// Crossfade command
+	r.addCommand(&discordgo.ApplicationCommand{
+		Name:        "crossfade",
+		Description: "Fade between tracks instead of cutting",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "seconds",
+				Description: "Crossfade length in seconds (0 to disable)",
+				Required:    true,
+				MinValue:    floatPtr(0),
+				MaxValue:    audio.MaxCrossfade.Seconds(),
+			},
+		},
+	}, handleCrossfade)
+
+	// Gapless command
+	r.addCommand(&discordgo.ApplicationCommand{
+		Name:        "gapless",
+		Description: "Start the next track without a gap",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "enabled",
+				Description: "Whether gapless playback is on",
+				Required:    true,
+			},
+		},
+	}, handleGapless)
+
+	// Filter command
+	r.addCommand(&discordgo.ApplicationCommand{
+		Name:        "filter",
+		Description: "Apply audio filters to playback",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "enable",
+				Description: "Turn on a filter preset",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "preset",
+						Description: "Filter to enable",
+						Required:    true,
+						Choices:     filterChoices(),
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "disable",
+				Description: "Turn off a filter preset",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "preset",
+						Description: "Filter to disable",
+						Required:    true,
+						Choices:     filterChoices(),
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "clear",
+				Description: "Turn off all filters",
+			},
+		},
+	}, handleFilter)
+
+	// Equalizer command
+	r.addCommand(&discordgo.ApplicationCommand{
+		Name:        "eq",
+		Description: "Adjust the 10-band equalizer",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "view",
+				Description: "Show the current EQ and saved presets",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "set",
+				Description: "Set the gain of one band",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "band",
+						Description: "Band to adjust",
+						Required:    true,
+						Choices:     eqBandChoices(),
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionNumber,
+						Name:        "gain",
+						Description: "Gain in dB",
+						Required:    true,
+						MinValue:    floatPtr(-audio.MaxEQGain),
+						MaxValue:    audio.MaxEQGain,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "reset",
+				Description: "Set every band back to flat",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "save",
+				Description: "Save the current EQ as a preset for this server",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "name",
+						Description: "Preset name",
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "load",
+				Description: "Load a saved preset",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "name",
+						Description: "Preset name",
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "delete",
+				Description: "Delete a saved preset",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "name",
+						Description: "Preset name",
+						Required:    true,
+					},
+				},
+			},
+		},
+	}, handleEQ)
+
+	// Normalize command
+	r.addCommand(&discordgo.ApplicationCommand{
+		Name:        "normalize",
+		Description: "Play every track at a consistent loudness",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "enabled",
+				Description: "Whether loudness normalization is on for this server",
+				Required:    true,
+			},
+		},
+	}, handleNormalize)
+
+	// Encoder command
+	r.addCommand(&discordgo.ApplicationCommand{
+		Name:        "encoder",
+		Description: "View or change the Opus encoder settings",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "bitrate",
+				Description: "Bitrate in kbps, capped at the voice channel's limit (0 for the default)",
+				Required:    false,
+				MinValue:    floatPtr(0),
+				MaxValue:    audio.MaxBitrate / 1000,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "application",
+				Description: "What the encoder is tuned for",
+				Required:    false,
+				Choices:     applicationChoices(),
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "fec",
+				Description: "Add forward error correction, used when packet loss is above 0%",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "packet-loss",
+				Description: "Expected packet loss in percent, trading quality for resilience",
+				Required:    false,
+				MinValue:    floatPtr(0),
+				MaxValue:    audio.MaxPacketLoss,
+			},
+		},
+	}, handleEncoder)
+
+	// Speed command
+	r.addCommand(&discordgo.ApplicationCommand{
+		Name:        "speed",
+		Description: "Change the playback speed",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionNumber,
+				Name:        "rate",
+				Description: "Speed multiplier (0.5-2.0)",
+				Required:    true,
+				MinValue:    floatPtr(audio.MinSpeed),
+				MaxValue:    audio.MaxSpeed,
+			},
+		},
+	}, handleSpeed)
+
+	// Pitch command
+	r.addCommand(&discordgo.ApplicationCommand{
+		Name:        "pitch",
+		Description: "Shift the pitch without changing the speed",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionNumber,
+				Name:        "semitones",
+				Description: "Semitones to shift by (-12 to 12, 0 to reset)",
+				Required:    true,
+				MinValue:    floatPtr(-audio.MaxPitch),
+				MaxValue:    audio.MaxPitch,
+			},
+		},
+	}, handlePitch)
+
+	// Autoplay command
+	r.addCommand(&discordgo.ApplicationCommand{
+		Name:        "autoplay",
+		Description: "Keep playing related tracks when the queue runs out",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "enabled",
+				Description: "Whether autoplay is on",
+				Required:    true,
+			},
+		},
+	}, handleAutoplay)
+
+	// Playlist command
+	r.addCommand(&discordgo.ApplicationCommand{
+		Name:        "playlist",
+		Description: "Manage saved playlists",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "create",
+				Description: "Create an empty playlist",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "name",
+						Description: "Playlist name",
+						Required:    true,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "server",
+						Description: "Share it with the whole server instead of keeping it to yourself",
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "add",
+				Description: "Add tracks to a playlist",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "name",
+						Description: "Playlist name",
+						Required:    true,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "query",
+						Description: "URL or search query",
+						Required:    true,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "server",
+						Description: "Pick the server playlist (true) or your own (false) when both have this name",
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "remove",
+				Description: "Remove a track from a playlist",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "name",
+						Description: "Playlist name",
+						Required:    true,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "position",
+						Description: "Position of the track to remove",
+						Required:    true,
+						MinValue:    floatPtr(1),
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "server",
+						Description: "Pick the server playlist (true) or your own (false) when both have this name",
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "list",
+				Description: "List your playlists and this server's",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "show",
+				Description: "Show the tracks in a playlist",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "name",
+						Description: "Playlist name",
+						Required:    true,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "server",
+						Description: "Pick the server playlist (true) or your own (false) when both have this name",
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "play",
+				Description: "Add a playlist to the queue",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "name",
+						Description: "Playlist name",
+						Required:    true,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "server",
+						Description: "Pick the server playlist (true) or your own (false) when both have this name",
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "delete",
+				Description: "Delete a playlist",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "name",
+						Description: "Playlist name",
+						Required:    true,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "server",
+						Description: "Pick the server playlist (true) or your own (false) when both have this name",
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "save-queue",
+				Description: "Save the current queue as a playlist",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "name",
+						Description: "Playlist name",
+						Required:    true,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "server",
+						Description: "Share it with the whole server instead of keeping it to yourself",
+					},
+				},
+			},
+		},
+	}, handlePlaylist)
+
+	// Settings command
+	r.addCommand(&discordgo.ApplicationCommand{
+		Name:        "settings",
+		Description: "Configure the bot for this server",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "view",
+				Description: "Show this server's settings",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "default-volume",
+				Description: "Set the volume playback starts at",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "level",
+						Description: "Volume level (0-100)",
+						Required:    true,
+						MinValue:    floatPtr(0),
+						MaxValue:    100,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "announce-channel",
+				Description: "Send now playing messages to a channel, or leave it out to use wherever playback started",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionChannel,
+						Name:         "channel",
+						Description:  "Announce channel",
+						ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "max-queue-length",
+				Description: "Limit how many tracks the queue can hold",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "tracks",
+						Description: "Most tracks in the queue, 0 for no limit",
+						Required:    true,
+						MinValue:    floatPtr(0),
+						MaxValue:    10000,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "max-track-duration",
+				Description: "Refuse to queue tracks longer than this",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "minutes",
+						Description: "Longest track in minutes, 0 for no limit",
+						Required:    true,
+						MinValue:    floatPtr(0),
+						MaxValue:    1440,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "idle-timeout",
+				Description: "Leave voice after nothing has played for a while",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "minutes",
+						Description: "Minutes to wait, 0 to stay",
+						Required:    true,
+						MinValue:    floatPtr(0),
+						MaxValue:    1440,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "dj-role",
+				Description: "Only let this role control playback, or leave it out to let everyone",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionRole,
+						Name:        "role",
+						Description: "DJ role",
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "vote-skip",
+				Description: "Make non-DJs vote to skip",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "percent",
+						Description: "Share of listeners who need to vote, 0 to turn vote-skip off",
+						Required:    true,
+						MinValue:    floatPtr(0),
+						MaxValue:    100,
+					},
+				},
+			},
+		},
+	}, handleSettings)
+
+	// Stats command
+	r.addCommand(&discordgo.ApplicationCommand{
+		Name:        "stats",
+		Description: "Show what this server has been listening to",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "period",
+				Description: "How far back to look (defaults to the past week)",
+				Choices:     statsPeriodChoices(),
+			},
+		},
+	}, handleStats)
+
 	// Now playing command
 	r.addCommand(&discordgo.ApplicationCommand{
 		Name:        "nowplaying",
@@ -183,6 +768,11 @@ func (r *Registry) registerCommands() {
 	r.componentHandlers["player_previous"] = handlePlayerPrevious
 	r.componentHandlers["player_stop"] = handlePlayerStop
 	r.componentHandlers["player_queue"] = handlePlayerQueue
+	r.componentHandlers["player_rewind"] = handlePlayerRewind
+	r.componentHandlers["player_forward"] = handlePlayerForward
+	r.componentHandlers["player_loop"] = handlePlayerLoop
+	r.componentHandlers["session_resume"] = handleSessionResume
+	r.componentHandlers["session_dismiss"] = handleSessionDismiss
 }
 
 func (r *Registry) addCommand(cmd *discordgo.ApplicationCommand, handler CommandHandler) {
@@ -212,7 +802,12 @@ func (r *Registry) UnregisterCommands() {
 }
 
 func (r *Registry) HandleCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	if handler, exists := r.handlers[i.ApplicationCommandData().Name]; exists {
+	data := i.ApplicationCommandData()
+	if handler, exists := r.handlers[data.Name]; exists {
+		if needsDJ(data) && !isDJ(s, i, r.bot) {
+			denyDJ(s, i)
+			return
+		}
 		handler(s, i, r.bot)
 	}
 }
@@ -220,6 +815,14 @@ func (r *Registry) HandleCommand(s *discordgo.Session, i *discordgo.InteractionC
 func (r *Registry) HandleComponent(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	customID := i.MessageComponentData().CustomID
 	if handler, exists := r.componentHandlers[customID]; exists {
+		if djComponents[customID] && !isDJ(s, i, r.bot) {
+			denyDJ(s, i)
+			return
+		}
+		if savedSessionComponents[customID] && !hasDJRole(i, r.bot) {
+			denyDJ(s, i)
+			return
+		}
 		handler(s, i, r.bot)
 	}
 }
@@ -0,0 +1,226 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dickeyy/meow/internal/audio"
+	"github.com/dickeyy/meow/internal/embeds"
+	"github.com/dickeyy/meow/internal/storage"
+)
+
+// Only this much history is kept in a saved session, enough for autoplay
+// and going back a few tracks
+const savedHistoryLimit = 50
+
+// SnapshotSession captures a session so it can be resumed after a restart.
+// It returns nil if there is nothing worth saving.
+func SnapshotSession(session *audio.Session) *storage.SavedSession {
+	vc := session.VoiceConnection()
+	tracks := session.Queue().All()
+	if vc == nil || len(tracks) == 0 || session.IsStopped() {
+		return nil
+	}
+
+	history := session.Queue().History()
+	if len(history) > savedHistoryLimit {
+		history = history[len(history)-savedHistoryLimit:]
+	}
+
+	var filters []string
+	for _, f := range session.Filters() {
+		filters = append(filters, string(f))
+	}
+	eq := session.EQ()
+	normalize := session.Normalize()
+
+	return &storage.SavedSession{
+		GuildID:        session.GuildID(),
+		VoiceChannelID: vc.ChannelID,
+		TextChannelID:  session.ChannelID(),
+		Tracks:         saveTracks(tracks),
+		History:        saveTracks(history),
+		Position:       session.Elapsed(),
+		Volume:         session.Volume(),
+		LoopMode:       session.LoopMode().String(),
+		Filters:        filters,
+		EQ:             eq[:],
+		Normalize:      &normalize,
+		Speed:          session.Speed(),
+		Pitch:          session.Pitch(),
+		Gapless:        session.Gapless(),
+		Crossfade:      session.Crossfade(),
+		Autoplay:       session.Autoplay(),
+		SavedAt:        time.Now(),
+	}
+}
+
+func saveTracks(tracks []*audio.Track) []storage.SavedTrack {
+	saved := make([]storage.SavedTrack, len(tracks))
+	for i, t := range tracks {
+		saved[i] = storage.SavedTrack{
+			ID:          t.ID,
+			Title:       t.Title,
+			Artist:      t.Artist,
+			Album:       t.Album,
+			Duration:    t.Duration,
+			URL:         t.URL,
+			Thumbnail:   t.Thumbnail,
+			Source:      string(t.Source),
+			RequestedBy: t.RequestedBy,
+			PlaylistID:  t.PlaylistID,
+			Loudness:    t.Loudness(),
+			TruePeak:    t.TruePeak(),
+		}
+	}
+	return saved
+}
+
+func restoreTracks(saved []storage.SavedTrack) []*audio.Track {
+	tracks := make([]*audio.Track, len(saved))
+	for i, t := range saved {
+		tracks[i] = &audio.Track{
+			ID:          t.ID,
+			Title:       t.Title,
+			Artist:      t.Artist,
+			Album:       t.Album,
+			Duration:    t.Duration,
+			URL:         t.URL,
+			Thumbnail:   t.Thumbnail,
+			Source:      audio.TrackSource(t.Source),
+			RequestedBy: t.RequestedBy,
+			PlaylistID:  t.PlaylistID,
+		}
+		tracks[i].SetLoudness(t.Loudness, t.TruePeak)
+	}
+	return tracks
+}
+
+// restoreSettings applies a saved session's playback settings. The session
+// isn't playing yet, so none of them restart anything.
+func restoreSettings(session *audio.Session, saved *storage.SavedSession) {
+	session.SetVolume(saved.Volume)
+	if mode, ok := audio.ParseLoopMode(saved.LoopMode); ok {
+		session.SetLoopMode(mode)
+	}
+	for _, name := range saved.Filters {
+		if f, ok := audio.ParseFilter(name); ok {
+			session.EnableFilter(f)
+		}
+	}
+
+	var eq audio.EQBands
+	copy(eq[:], saved.EQ)
+	session.SetEQ(eq)
+	if saved.Normalize != nil {
+		session.SetNormalize(*saved.Normalize)
+	}
+
+	if saved.Speed > 0 {
+		session.SetSpeed(saved.Speed)
+	}
+	session.SetPitch(saved.Pitch)
+	session.SetGapless(saved.Gapless)
+	session.SetCrossfade(saved.Crossfade)
+	session.SetAutoplay(saved.Autoplay)
+}
+
+// OfferResume posts a message in the session's old text channel asking
+// whether to pick up where it left off
+func OfferResume(s *discordgo.Session, saved *storage.SavedSession) error {
+	current := saved.Tracks[0]
+	description := fmt.Sprintf("I was playing **%s** at %s with %d more in the queue before restarting.",
+		current.Title, embeds.FormatDuration(saved.Position), len(saved.Tracks)-1)
+
+	_, err := s.ChannelMessageSendComplex(saved.TextChannelID, &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{embeds.Info("Resume Playback?", description)},
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.Button{
+						CustomID: "session_resume",
+						Label:    "Resume",
+						Style:    discordgo.SuccessButton,
+					},
+					discordgo.Button{
+						CustomID: "session_dismiss",
+						Label:    "Dismiss",
+						Style:    discordgo.SecondaryButton,
+					},
+				},
+			},
+		},
+	})
+	return err
+}
+
+func handleSessionResume(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInterface) {
+	store := bot.Storage()
+	if store == nil {
+		updateMessage(s, i, embeds.Error("Error", "Nothing to resume"), []discordgo.MessageComponent{})
+		return
+	}
+
+	saved, err := store.GetSavedSession(i.GuildID)
+	if err != nil || saved == nil || len(saved.Tracks) == 0 {
+		updateMessage(s, i, embeds.Error("Error", "Nothing to resume"), []discordgo.MessageComponent{})
+		return
+	}
+
+	session := bot.GetOrCreateSession(i.GuildID)
+	if !session.IsStopped() || !session.Queue().IsEmpty() {
+		respondComponent(s, i, embeds.Error("Error", "Something is already playing"))
+		return
+	}
+
+	// Joining and resolving the first track can take a while
+	acknowledgeComponent(s, i)
+
+	vc, err := s.ChannelVoiceJoin(i.GuildID, saved.VoiceChannelID, false, true)
+	if err != nil {
+		editResumeOffer(s, i, embeds.Error("Error", "Failed to join voice channel: "+err.Error()))
+		return
+	}
+	session.SetVoiceConnection(vc)
+	session.SetChannelID(saved.TextChannelID)
+	if channel, err := s.State.Channel(saved.VoiceChannelID); err == nil {
+		session.SetChannelBitrate(channel.Bitrate)
+	}
+
+	restoreSettings(session, saved)
+	session.Queue().Add(restoreTracks(saved.Tracks)...)
+	session.Queue().SetHistory(restoreTracks(saved.History))
+
+	if err := startPlayback(s, bot, session, saved.Position); err != nil {
+		fmt.Printf("[resume] Failed to resolve first track: %v\n", err)
+		session.Queue().ClearAll()
+		editResumeOffer(s, i, embeds.Error("Error", "Failed to get stream URL: "+err.Error()))
+		return
+	}
+
+	if err := store.DeleteSavedSession(i.GuildID); err != nil {
+		fmt.Printf("[resume] Failed to delete saved session: %v\n", err)
+	}
+
+	editResumeOffer(s, i, embeds.Success("Resumed", fmt.Sprintf("Picked up **%s** where it left off", saved.Tracks[0].Title)))
+}
+
+func handleSessionDismiss(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInterface) {
+	if store := bot.Storage(); store != nil {
+		if err := store.DeleteSavedSession(i.GuildID); err != nil {
+			fmt.Printf("[resume] Failed to delete saved session: %v\n", err)
+		}
+	}
+
+	updateMessage(s, i, embeds.Info("Resume Playback?", "Dismissed, the old queue has been cleared"), []discordgo.MessageComponent{})
+}
+
+// editResumeOffer replaces the resume offer once the interaction has been
+// acknowledged, removing its buttons
+func editResumeOffer(s *discordgo.Session, i *discordgo.InteractionCreate, embed *discordgo.MessageEmbed) {
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds:     &[]*discordgo.MessageEmbed{embed},
+		Components: &[]discordgo.MessageComponent{},
+	})
+}
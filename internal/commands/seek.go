@@ -0,0 +1,157 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dickeyy/meow/internal/embeds"
+)
+
+// How far the Rewind/Forward buttons jump
+const seekStep = 10 * time.Second
+
+func handleSeek(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInterface) {
+	session := bot.GetSession(i.GuildID)
+	if session == nil || session.IsStopped() {
+		respond(s, i, embeds.Error("Error", "Nothing is playing"))
+		return
+	}
+
+	track := session.Queue().Current()
+	if track == nil {
+		respond(s, i, embeds.Error("Error", "Nothing is playing"))
+		return
+	}
+
+	if track.Duration == 0 {
+		respond(s, i, embeds.Error("Error", "Can't seek in a live stream"))
+		return
+	}
+
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		respond(s, i, embeds.Error("Error", "Please provide a position"))
+		return
+	}
+
+	position, err := parseSeekPosition(options[0].StringValue(), session.Elapsed())
+	if err != nil {
+		respond(s, i, embeds.Error("Error", "Invalid position. Use a timestamp like `1:23`, `83` or `1m23s`, or an offset like `+30` or `-15s`"))
+		return
+	}
+
+	if position >= track.Duration {
+		respond(s, i, embeds.Error("Error", fmt.Sprintf("Position is past the end of the track (`%s`)", track.FormatDuration())))
+		return
+	}
+
+	session.Seek(position)
+	respond(s, i, embeds.Success("Seeked", fmt.Sprintf("Jumped to `%s` in **%s**", embeds.FormatDuration(position), track.Title)))
+}
+
+// parseSeekPosition accepts an absolute timestamp ("1:23", "1:02:03", "83")
+// or duration ("1h2m"), or either as an offset from the current position
+// ("+30", "-1:00", "-15s")
+func parseSeekPosition(input string, elapsed time.Duration) (time.Duration, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return 0, fmt.Errorf("empty position")
+	}
+
+	sign := 0
+	switch input[0] {
+	case '+':
+		sign = 1
+		input = input[1:]
+	case '-':
+		sign = -1
+		input = input[1:]
+	}
+
+	offset, err := parseTimestamp(input)
+	if err != nil {
+		return 0, err
+	}
+
+	position := offset
+	if sign != 0 {
+		position = elapsed + time.Duration(sign)*offset
+	}
+	if position < 0 {
+		position = 0
+	}
+
+	return position, nil
+}
+
+// parseTimestamp reads "1:02:03" style timestamps, bare seconds, and Go
+// style durations like "1h2m" or "15s"
+func parseTimestamp(input string) (time.Duration, error) {
+	if strings.ContainsAny(input, "hms") {
+		d, err := time.ParseDuration(input)
+		if err != nil || d < 0 {
+			return 0, fmt.Errorf("invalid duration %q", input)
+		}
+		return d, nil
+	}
+
+	parts := strings.Split(input, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("too many fields in %q", input)
+	}
+
+	total := 0
+	for idx, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid field %q", part)
+		}
+		// Minutes and seconds after the leading field must be under 60
+		if idx > 0 && n >= 60 {
+			return 0, fmt.Errorf("invalid field %q", part)
+		}
+		total = total*60 + n
+	}
+
+	return time.Duration(total) * time.Second, nil
+}
+
+// Component handlers for the Rewind/Forward buttons
+func handlePlayerRewind(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInterface) {
+	seekRelative(s, i, bot, -seekStep)
+}
+
+func handlePlayerForward(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInterface) {
+	seekRelative(s, i, bot, seekStep)
+}
+
+func seekRelative(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInterface, delta time.Duration) {
+	session := bot.GetSession(i.GuildID)
+	if session == nil || session.IsStopped() {
+		respondComponent(s, i, embeds.Error("Error", "Nothing is playing"))
+		return
+	}
+
+	track := session.Queue().Current()
+	if track == nil {
+		respondComponent(s, i, embeds.Error("Error", "Nothing is playing"))
+		return
+	}
+
+	if track.Duration == 0 {
+		respondComponent(s, i, embeds.Error("Error", "Can't seek in a live stream"))
+		return
+	}
+
+	position := session.Elapsed() + delta
+	if position >= track.Duration {
+		respondComponent(s, i, embeds.Error("Error", "Already at the end of the track"))
+		return
+	}
+
+	session.Seek(position)
+	updateNowPlaying(s, i, track, session)
+}
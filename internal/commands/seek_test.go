@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSeekPosition(t *testing.T) {
+	const elapsed = time.Minute
+
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{input: "1:23", want: 83 * time.Second},
+		{input: "83", want: 83 * time.Second},
+		{input: "1:02:03", want: time.Hour + 2*time.Minute + 3*time.Second},
+		{input: " 0:05 ", want: 5 * time.Second},
+		{input: "1h2m", want: time.Hour + 2*time.Minute},
+		{input: "1m30s", want: 90 * time.Second},
+		{input: "+10", want: elapsed + 10*time.Second},
+		{input: "-15s", want: elapsed - 15*time.Second},
+		{input: "-1:00", want: 0},
+		{input: "-5m", want: 0},
+		{input: "", wantErr: true},
+		{input: "+", wantErr: true},
+		{input: "1:60", wantErr: true},
+		{input: "1:2:3:4", wantErr: true},
+		{input: "abc", wantErr: true},
+		{input: "1x", wantErr: true},
+		{input: "--5", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseSeekPosition(tt.input, elapsed)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseSeekPosition(%q) = %v, want an error", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSeekPosition(%q) failed: %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseSeekPosition(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dickeyy/meow/internal/audio"
+	"github.com/dickeyy/meow/internal/embeds"
+	"github.com/dickeyy/meow/internal/storage"
+)
+
+func handleSettings(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInterface) {
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		respond(s, i, embeds.Error("Error", "Please specify a subcommand"))
+		return
+	}
+
+	subCmd := options[0]
+
+	// Anyone can look, only managers can change anything
+	if subCmd.Name != "view" && !canManageServer(i) {
+		respond(s, i, embeds.Error("Error", "You need Manage Server to change the bot's settings"))
+		return
+	}
+
+	store := bot.Storage()
+	if store == nil || !store.Persistent() {
+		respond(s, i, embeds.Error("Error", "Settings require a database"))
+		return
+	}
+
+	settings, err := store.GetGuildSettings(i.GuildID)
+	if err != nil {
+		respond(s, i, embeds.Error("Error", "Failed to load guild settings"))
+		return
+	}
+
+	switch subCmd.Name {
+	case "view":
+		handleSettingsView(s, i, settings)
+	case "default-volume":
+		handleSettingsDefaultVolume(s, i, store, settings, subCmd.Options)
+	case "announce-channel":
+		handleSettingsAnnounceChannel(s, i, store, settings, subCmd.Options)
+	case "max-queue-length":
+		handleSettingsMaxQueueLength(s, i, store, settings, subCmd.Options)
+	case "max-track-duration":
+		handleSettingsMaxTrackDuration(s, i, store, settings, subCmd.Options)
+	case "idle-timeout":
+		handleSettingsIdleTimeout(s, i, store, settings, subCmd.Options)
+	case "dj-role":
+		handleSettingsDJRole(s, i, store, settings, subCmd.Options)
+	case "vote-skip":
+		handleSettingsVoteSkip(s, i, store, settings, subCmd.Options)
+	}
+}
+
+func handleSettingsView(s *discordgo.Session, i *discordgo.InteractionCreate, settings *storage.GuildSettings) {
+	djRole := "Everyone"
+	if settings.DJRoleID != "" {
+		djRole = fmt.Sprintf("<@&%s>", settings.DJRoleID)
+	}
+
+	voteSkip := "Off"
+	if settings.VoteSkipPercent > 0 {
+		voteSkip = fmt.Sprintf("%d%% of listeners", settings.VoteSkipPercent)
+	}
+
+	announce := "Where playback was started"
+	if settings.AnnounceChannelID != "" {
+		announce = fmt.Sprintf("<#%s>", settings.AnnounceChannelID)
+	}
+
+	maxQueue := "No limit"
+	if settings.MaxQueueLength > 0 {
+		maxQueue = pluralTracks(settings.MaxQueueLength)
+	}
+
+	maxDuration := "No limit"
+	if settings.MaxTrackDuration > 0 {
+		maxDuration = formatMinutes(settings.MaxTrackDuration)
+	}
+
+	idle := "Never leave"
+	if settings.IdleTimeout > 0 {
+		idle = "After " + formatMinutes(settings.IdleTimeout)
+	}
+
+	embed := embeds.Info("Settings", "")
+	embed.Fields = []*discordgo.MessageEmbedField{
+		{Name: "Default Volume", Value: fmt.Sprintf("%d%%", settings.DefaultVolume), Inline: true},
+		{Name: "DJ Role", Value: djRole, Inline: true},
+		{Name: "Vote-Skip", Value: voteSkip, Inline: true},
+		{Name: "Announce Channel", Value: announce, Inline: true},
+		{Name: "Max Queue Length", Value: maxQueue, Inline: true},
+		{Name: "Max Track Duration", Value: maxDuration, Inline: true},
+		{Name: "Idle Timeout", Value: idle, Inline: true},
+	}
+	respond(s, i, embed)
+}
+
+func handleSettingsDefaultVolume(s *discordgo.Session, i *discordgo.InteractionCreate, store *storage.Storage, settings *storage.GuildSettings, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	for _, opt := range options {
+		if opt.Name == "level" {
+			settings.DefaultVolume = int(opt.IntValue())
+		}
+	}
+
+	if !saveSettings(s, i, store, settings) {
+		return
+	}
+	respond(s, i, embeds.Success("Settings", fmt.Sprintf("Playback will start at **%d%%** volume from the next time the bot joins", settings.DefaultVolume)))
+}
+
+func handleSettingsAnnounceChannel(s *discordgo.Session, i *discordgo.InteractionCreate, store *storage.Storage, settings *storage.GuildSettings, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	settings.AnnounceChannelID = ""
+	for _, opt := range options {
+		if opt.Name == "channel" {
+			settings.AnnounceChannelID = opt.ChannelValue(nil).ID
+		}
+	}
+
+	if !saveSettings(s, i, store, settings) {
+		return
+	}
+
+	if settings.AnnounceChannelID == "" {
+		respond(s, i, embeds.Success("Settings", "Now playing messages will go wherever playback was started"))
+		return
+	}
+	respond(s, i, embeds.Success("Settings", fmt.Sprintf("Now playing messages will go to <#%s>", settings.AnnounceChannelID)))
+}
+
+func handleSettingsMaxQueueLength(s *discordgo.Session, i *discordgo.InteractionCreate, store *storage.Storage, settings *storage.GuildSettings, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	for _, opt := range options {
+		if opt.Name == "tracks" {
+			settings.MaxQueueLength = int(opt.IntValue())
+		}
+	}
+
+	if !saveSettings(s, i, store, settings) {
+		return
+	}
+
+	if settings.MaxQueueLength == 0 {
+		respond(s, i, embeds.Success("Settings", "The queue can be any length"))
+		return
+	}
+	respond(s, i, embeds.Success("Settings", fmt.Sprintf("The queue can hold up to **%s**", pluralTracks(settings.MaxQueueLength))))
+}
+
+func handleSettingsMaxTrackDuration(s *discordgo.Session, i *discordgo.InteractionCreate, store *storage.Storage, settings *storage.GuildSettings, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	for _, opt := range options {
+		if opt.Name == "minutes" {
+			settings.MaxTrackDuration = int(opt.IntValue())
+		}
+	}
+
+	if !saveSettings(s, i, store, settings) {
+		return
+	}
+
+	if settings.MaxTrackDuration == 0 {
+		respond(s, i, embeds.Success("Settings", "Tracks can be any length"))
+		return
+	}
+	respond(s, i, embeds.Success("Settings", fmt.Sprintf("Tracks longer than **%s** won't be queued", formatMinutes(settings.MaxTrackDuration))))
+}
+
+func handleSettingsIdleTimeout(s *discordgo.Session, i *discordgo.InteractionCreate, store *storage.Storage, settings *storage.GuildSettings, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	for _, opt := range options {
+		if opt.Name == "minutes" {
+			settings.IdleTimeout = int(opt.IntValue())
+		}
+	}
+
+	if !saveSettings(s, i, store, settings) {
+		return
+	}
+
+	if settings.IdleTimeout == 0 {
+		respond(s, i, embeds.Success("Settings", "The bot will stay in voice when nothing is playing"))
+		return
+	}
+	respond(s, i, embeds.Success("Settings", fmt.Sprintf("The bot will leave voice after **%s** with nothing playing", formatMinutes(settings.IdleTimeout))))
+}
+
+func handleSettingsDJRole(s *discordgo.Session, i *discordgo.InteractionCreate, store *storage.Storage, settings *storage.GuildSettings, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	settings.DJRoleID = ""
+	for _, opt := range options {
+		if opt.Name == "role" {
+			settings.DJRoleID = opt.RoleValue(nil, i.GuildID).ID
+		}
+	}
+
+	if !saveSettings(s, i, store, settings) {
+		return
+	}
+
+	if settings.DJRoleID == "" {
+		respond(s, i, embeds.Success("Settings", "Removed the DJ role, everyone can control playback"))
+		return
+	}
+	respond(s, i, embeds.Success("Settings", fmt.Sprintf("Only <@&%s> can control playback now, along with whoever requested the current track and anyone alone with the bot", settings.DJRoleID)))
+}
+
+func handleSettingsVoteSkip(s *discordgo.Session, i *discordgo.InteractionCreate, store *storage.Storage, settings *storage.GuildSettings, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	for _, opt := range options {
+		if opt.Name == "percent" {
+			settings.VoteSkipPercent = int(opt.IntValue())
+		}
+	}
+
+	if !saveSettings(s, i, store, settings) {
+		return
+	}
+
+	if settings.VoteSkipPercent == 0 {
+		respond(s, i, embeds.Success("Settings", "Vote-skip is off"))
+		return
+	}
+	respond(s, i, embeds.Success("Settings", fmt.Sprintf("Skipping now takes votes from **%d%%** of listeners, unless you're a DJ", settings.VoteSkipPercent)))
+}
+
+// saveSettings stores changed guild settings, responding with an error if
+// that fails
+func saveSettings(s *discordgo.Session, i *discordgo.InteractionCreate, store *storage.Storage, settings *storage.GuildSettings) bool {
+	if err := store.SaveGuildSettings(settings); err != nil {
+		fmt.Printf("[settings] Failed to save settings: %v\n", err)
+		respond(s, i, embeds.Error("Error", "Failed to save settings"))
+		return false
+	}
+	return true
+}
+
+// applyQueueLimits drops tracks longer than the server allows and any that
+// would take the queue past its maximum length. The note explains what was
+// left out, if anything.
+func applyQueueLimits(bot BotInterface, session *audio.Session, tracks []*audio.Track) ([]*audio.Track, string) {
+	settings := guildSettings(bot, session.GuildID())
+	if settings == nil {
+		return tracks, ""
+	}
+
+	var notes []string
+
+	if settings.MaxTrackDuration > 0 {
+		limit := time.Duration(settings.MaxTrackDuration) * time.Minute
+		kept := tracks[:0:0]
+		for _, track := range tracks {
+			// Tracks of unknown length get the benefit of the doubt
+			if track.Duration <= limit {
+				kept = append(kept, track)
+			}
+		}
+		if dropped := len(tracks) - len(kept); dropped > 0 {
+			notes = append(notes, fmt.Sprintf("Left out %s longer than %s", pluralTracks(dropped), formatMinutes(settings.MaxTrackDuration)))
+		}
+		tracks = kept
+	}
+
+	if settings.MaxQueueLength > 0 && len(tracks) > 0 {
+		room := settings.MaxQueueLength - session.Queue().Len()
+		if room < 0 {
+			room = 0
+		}
+		if room < len(tracks) {
+			notes = append(notes, fmt.Sprintf("Left out %s, the queue is limited to %d", pluralTracks(len(tracks)-room), settings.MaxQueueLength))
+			tracks = tracks[:room]
+		}
+	}
+
+	return tracks, strings.Join(notes, "\n")
+}
+
+// announceChannel is where now playing messages go: the server's announce
+// channel if it has one, otherwise the channel playback was started from
+func announceChannel(bot BotInterface, session *audio.Session) string {
+	if settings := guildSettings(bot, session.GuildID()); settings != nil && settings.AnnounceChannelID != "" {
+		return settings.AnnounceChannelID
+	}
+	return session.ChannelID()
+}
+
+func pluralTracks(n int) string {
+	if n == 1 {
+		return "1 track"
+	}
+	return fmt.Sprintf("%d tracks", n)
+}
+
+func formatMinutes(minutes int) string {
+	if minutes == 1 {
+		return "1 minute"
+	}
+	return fmt.Sprintf("%d minutes", minutes)
+}
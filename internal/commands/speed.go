@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dickeyy/meow/internal/embeds"
+)
+
+func handleSpeed(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInterface) {
+	session := bot.GetSession(i.GuildID)
+	if session == nil {
+		respond(s, i, embeds.Error("Error", "No active session. Start playing something first."))
+		return
+	}
+
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		respond(s, i, embeds.Info("Speed", fmt.Sprintf("Playback speed: **%.2fx**", session.Speed())))
+		return
+	}
+
+	session.SetSpeed(options[0].FloatValue())
+	respond(s, i, embeds.Success("Speed", fmt.Sprintf("Playback speed set to **%.2fx**", session.Speed())))
+}
+
+func handlePitch(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInterface) {
+	session := bot.GetSession(i.GuildID)
+	if session == nil {
+		respond(s, i, embeds.Error("Error", "No active session. Start playing something first."))
+		return
+	}
+
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		respond(s, i, embeds.Info("Pitch", fmt.Sprintf("Pitch: **%+.1f semitones**", session.Pitch())))
+		return
+	}
+
+	session.SetPitch(options[0].FloatValue())
+
+	if session.Pitch() == 0 {
+		respond(s, i, embeds.Success("Pitch", "Pitch reset"))
+		return
+	}
+	respond(s, i, embeds.Success("Pitch", fmt.Sprintf("Pitch shifted by **%+.1f semitones**", session.Pitch())))
+}
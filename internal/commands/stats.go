@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dickeyy/meow/internal/audio"
+	"github.com/dickeyy/meow/internal/embeds"
+	"github.com/dickeyy/meow/internal/storage"
+)
+
+// How many entries each of the top lists in /stats shows
+const statsTopLimit = 5
+
+// statsPeriod is a window /stats can cover
+type statsPeriod struct {
+	name   string
+	label  string
+	window time.Duration // 0 for all time
+}
+
+var statsPeriods = []statsPeriod{
+	{name: "day", label: "Past 24 hours", window: 24 * time.Hour},
+	{name: "week", label: "Past 7 days", window: 7 * 24 * time.Hour},
+	{name: "all", label: "All time"},
+}
+
+func handleStats(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInterface) {
+	store := bot.Storage()
+	if store == nil || !store.Persistent() {
+		respond(s, i, embeds.Error("Error", "Listening stats require a database"))
+		return
+	}
+
+	period := statsPeriods[1]
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Name == "period" {
+			for _, p := range statsPeriods {
+				if p.name == opt.StringValue() {
+					period = p
+				}
+			}
+		}
+	}
+
+	var since time.Time
+	if period.window > 0 {
+		since = time.Now().Add(-period.window)
+	}
+
+	stats, err := store.GetGuildStats(i.GuildID, since, statsTopLimit)
+	if err != nil {
+		fmt.Printf("[stats] Failed to load stats: %v\n", err)
+		respond(s, i, embeds.Error("Error", "Failed to load listening stats"))
+		return
+	}
+
+	if stats.Plays == 0 {
+		respond(s, i, embeds.Info("Listening Stats", fmt.Sprintf("**%s:** Nothing has been played yet", period.label)))
+		return
+	}
+
+	respond(s, i, embeds.Stats(stats, period.label))
+}
+
+// recordPlay adds a finished or skipped track to the guild's play history
+func recordPlay(store *storage.Storage, guildID string, play audio.TrackPlay) {
+	track := play.Track
+
+	// Tracks cut off straight away, e.g. skipped while loading, aren't
+	// worth counting
+	if play.Listened < time.Second {
+		return
+	}
+
+	err := store.RecordPlay(&storage.PlayRecord{
+		GuildID:     guildID,
+		TrackID:     track.ID,
+		Title:       track.Title,
+		Artist:      track.Artist,
+		URL:         track.URL,
+		Source:      string(track.Source),
+		Duration:    track.Duration,
+		RequestedBy: track.RequestedBy,
+		StartedAt:   play.StartedAt,
+		EndedAt:     play.EndedAt,
+		Listened:    play.Listened,
+		Skipped:     play.Skipped,
+	})
+	if err != nil {
+		fmt.Printf("[stats] Failed to record play of %s: %v\n", track.Title, err)
+	}
+}
+
+func statsPeriodChoices() []*discordgo.ApplicationCommandOptionChoice {
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, len(statsPeriods))
+	for i, p := range statsPeriods {
+		choices[i] = &discordgo.ApplicationCommandOptionChoice{
+			Name:  p.label,
+			Value: p.name,
+		}
+	}
+	return choices
+}
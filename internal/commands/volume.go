@@ -17,16 +17,17 @@ func handleVolume(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotI
 	options := i.ApplicationCommandData().Options
 	if len(options) == 0 {
 		// Show current volume
-		respond(s, i, embeds.Info("Volume", fmt.Sprintf("Current volume: **%d%%**", session.Volume())))
+		level := session.Volume()
+		respond(s, i, embeds.Info("Volume", fmt.Sprintf("%s **%d%%**", createVolumeBar(level), level)))
 		return
 	}
 
-	level := int(options[0].IntValue())
-	session.SetVolume(level)
+	session.SetVolume(int(options[0].IntValue()))
 
-	// Create volume bar visualization
+	// Read back the clamped level the player is now ramping to
+	level := session.Volume()
 	volumeBar := createVolumeBar(level)
-	
+
 	respond(s, i, embeds.Success("Volume", fmt.Sprintf("%s **%d%%**", volumeBar, level)))
 }
 
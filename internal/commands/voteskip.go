@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dickeyy/meow/internal/audio"
+	"github.com/dickeyy/meow/internal/embeds"
+)
+
+// skipDecision is what happens when someone asks to skip
+type skipDecision int
+
+const (
+	skipNow skipDecision = iota
+	skipVote
+	skipDenied
+)
+
+// decideSkip works out whether the user's skip goes straight through. DJs
+// always skip, and with vote-skip on everyone else gets a vote. Without it
+// only a DJ role keeps them from skipping.
+func decideSkip(s *discordgo.Session, i *discordgo.InteractionCreate, bot BotInterface) (skipDecision, int) {
+	settings := guildSettings(bot, i.GuildID)
+	if settings == nil || hasDJRights(s, i, bot, settings) {
+		return skipNow, 0
+	}
+	if settings.VoteSkipPercent > 0 {
+		return skipVote, settings.VoteSkipPercent
+	}
+	if settings.DJRoleID == "" {
+		return skipNow, 0
+	}
+	return skipDenied, 0
+}
+
+// skipVoteResult is the tally after a vote
+type skipVoteResult struct {
+	votes  int
+	needed int
+	added  bool // False if the user had already voted
+}
+
+func (r skipVoteResult) passed() bool {
+	return r.votes >= r.needed
+}
+
+// castSkipVote records a vote to skip the current track, needing percent
+// of the people listening in the bot's channel. Bots and anyone deafened
+// aren't listening, and can't vote. The error is fit to show the user.
+func castSkipVote(s *discordgo.Session, i *discordgo.InteractionCreate, session *audio.Session, percent int) (skipVoteResult, error) {
+	track := session.Queue().Current()
+	vc := session.VoiceConnection()
+	if track == nil || vc == nil {
+		return skipVoteResult{}, fmt.Errorf("Nothing is playing")
+	}
+
+	// Who is listening is worked out again on every vote, so people who
+	// have left since don't hold the vote up
+	listeners := skipVoters(s, i.GuildID, vc.ChannelID)
+	if !slices.Contains(listeners, i.Member.User.ID) {
+		return skipVoteResult{}, fmt.Errorf("You need to be listening in <#%s> to vote", vc.ChannelID)
+	}
+
+	needed := skipVotesNeeded(len(listeners), percent)
+	votes, added := session.VoteSkip(track, i.Member.User.ID, listeners, needed)
+	return skipVoteResult{votes: votes, needed: needed, added: added}, nil
+}
+
+// RecountSkipVote updates a vote in progress when people join, leave or
+// deafen, skipping the track if the votes already cast are now enough
+func RecountSkipVote(s *discordgo.Session, bot BotInterface, session *audio.Session) {
+	if votes, _ := session.SkipVotes(); votes == 0 {
+		return
+	}
+	vc := session.VoiceConnection()
+	settings := guildSettings(bot, session.GuildID())
+	if vc == nil || settings == nil || settings.VoteSkipPercent == 0 {
+		return
+	}
+
+	listeners := skipVoters(s, session.GuildID(), vc.ChannelID)
+	votes, needed := session.RecountSkipVotes(listeners, skipVotesNeeded(len(listeners), settings.VoteSkipPercent))
+	if votes > 0 && votes >= needed {
+		session.ClearSkipVotes()
+		session.Skip()
+		return
+	}
+	refreshNowPlaying(s, session)
+}
+
+// skipVoters returns the IDs of the people listening in channelID, who are
+// the ones that can vote. Bots and anyone deafened aren't listening.
+func skipVoters(s *discordgo.Session, guildID, channelID string) []string {
+	var voters []string
+	for _, vs := range voiceListeners(s, guildID, channelID) {
+		if !vs.Deaf && !vs.SelfDeaf {
+			voters = append(voters, vs.UserID)
+		}
+	}
+	return voters
+}
+
+// skipVotesNeeded is how many votes make up percent of listeners, rounding
+// up so 50% of 3 listeners is 2
+func skipVotesNeeded(listeners, percent int) int {
+	return max(1, (listeners*percent+99)/100)
+}
+
+// refreshNowPlaying redraws the session's now playing message, e.g. to show
+// a new vote
+func refreshNowPlaying(s *discordgo.Session, session *audio.Session) {
+	track := session.Queue().Current()
+	channelID, messageID := session.NowPlayingMessage()
+	if track == nil || messageID == "" {
+		return
+	}
+
+	embed := embeds.NowPlaying(track, session)
+	components := embeds.PlayerButtons(session)
+	_, err := s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+		ID:         messageID,
+		Channel:    channelID,
+		Embeds:     &[]*discordgo.MessageEmbed{embed},
+		Components: &components,
+	})
+	if err != nil {
+		fmt.Printf("[skip] Failed to update now playing message: %v\n", err)
+	}
+}
@@ -16,13 +16,15 @@ type Config struct {
 	RedisURL            string
 	YouTubeCookiesPath  string
 	DefaultVolume       int
+	PrefetchDepth       int
+	OpusBitrate         int    // kbps, 0 to match the voice channel
+	OpusApplication     string // voip, audio or lowdelay
+	OpusFEC             bool   // In-band forward error correction
+	OpusPacketLoss      int    // Expected packet loss in percent
 }
 
 func Load() (*Config, error) {
-	if err := godotenv.Load(); err != nil {
-		// .env file is optional in production
-		fmt.Println("No .env file found, using environment variables")
-	}
+	loadEnv()
 
 	cfg := &Config{
 		DiscordToken:        os.Getenv("DISCORD_TOKEN"),
@@ -32,6 +34,9 @@ func Load() (*Config, error) {
 		RedisURL:            os.Getenv("REDIS_URL"),
 		YouTubeCookiesPath:  os.Getenv("YOUTUBE_COOKIES_PATH"),
 		DefaultVolume:       50,
+		PrefetchDepth:       2,
+		OpusBitrate:         0,
+		OpusApplication:     "audio",
 	}
 
 	if vol := os.Getenv("DEFAULT_VOLUME"); vol != "" {
@@ -40,9 +45,59 @@ func Load() (*Config, error) {
 		}
 	}
 
+	if depth := os.Getenv("PREFETCH_DEPTH"); depth != "" {
+		if d, err := strconv.Atoi(depth); err == nil && d >= 0 {
+			cfg.PrefetchDepth = d
+		}
+	}
+
+	if bitrate := os.Getenv("OPUS_BITRATE"); bitrate != "" {
+		if b, err := strconv.Atoi(bitrate); err == nil && (b == 0 || (b >= 8 && b <= 510)) {
+			cfg.OpusBitrate = b
+		}
+	}
+
+	if app := os.Getenv("OPUS_APPLICATION"); app != "" {
+		switch app {
+		case "voip", "audio", "lowdelay":
+			cfg.OpusApplication = app
+		}
+	}
+
+	if fec := os.Getenv("OPUS_FEC"); fec != "" {
+		if f, err := strconv.ParseBool(fec); err == nil {
+			cfg.OpusFEC = f
+		}
+	}
+
+	if loss := os.Getenv("OPUS_PACKET_LOSS"); loss != "" {
+		if l, err := strconv.Atoi(loss); err == nil && l >= 0 && l <= 100 {
+			cfg.OpusPacketLoss = l
+		}
+	}
+
 	if cfg.DiscordToken == "" {
 		return nil, fmt.Errorf("DISCORD_TOKEN is required")
 	}
 
 	return cfg, nil
 }
+
+// LoadPostgresURL reads only the database URL, for commands that work on
+// the database without running the bot
+func LoadPostgresURL() (string, error) {
+	loadEnv()
+
+	url := os.Getenv("POSTGRES_URL")
+	if url == "" {
+		return "", fmt.Errorf("POSTGRES_URL is required")
+	}
+	return url, nil
+}
+
+func loadEnv() {
+	if err := godotenv.Load(); err != nil {
+		// .env file is optional in production
+		fmt.Println("No .env file found, using environment variables")
+	}
+}
@@ -1,18 +1,20 @@
 package embeds
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/dickeyy/meow/internal/audio"
 )
 
 // Discord's default dark theme embed color (no visible border)
 const ColorDefault = 0x2B2D31
 
-func PlayerButtons(isPaused bool) []discordgo.MessageComponent {
+func PlayerButtons(session *audio.Session) []discordgo.MessageComponent {
 	var playPauseButton discordgo.Button
 
-	if isPaused {
+	if session.IsPaused() {
 		playPauseButton = discordgo.Button{
 			CustomID: "player_resume",
 			Label:    "Play",
@@ -26,6 +28,23 @@ func PlayerButtons(isPaused bool) []discordgo.MessageComponent {
 		}
 	}
 
+	// Show the vote tally while a vote to skip is going
+	skipButton := discordgo.Button{
+		CustomID: "player_skip",
+		Label:    "Skip",
+		Style:    discordgo.SecondaryButton,
+	}
+	if votes, needed := session.SkipVotes(); votes > 0 {
+		skipButton.Label = fmt.Sprintf("Skip (%d/%d)", votes, needed)
+		skipButton.Style = discordgo.PrimaryButton
+	}
+
+	loopMode := session.LoopMode()
+	loopStyle := discordgo.SecondaryButton
+	if loopMode != audio.LoopOff {
+		loopStyle = discordgo.SuccessButton
+	}
+
 	return []discordgo.MessageComponent{
 		discordgo.ActionsRow{
 			Components: []discordgo.MessageComponent{
@@ -35,11 +54,7 @@ func PlayerButtons(isPaused bool) []discordgo.MessageComponent {
 					Style:    discordgo.SecondaryButton,
 				},
 				playPauseButton,
-				discordgo.Button{
-					CustomID: "player_skip",
-					Label:    "Skip",
-					Style:    discordgo.SecondaryButton,
-				},
+				skipButton,
 				discordgo.Button{
 					CustomID: "player_queue",
 					Label:    "Queue",
@@ -52,6 +67,25 @@ func PlayerButtons(isPaused bool) []discordgo.MessageComponent {
 				},
 			},
 		},
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					CustomID: "player_rewind",
+					Label:    "Rewind",
+					Style:    discordgo.SecondaryButton,
+				},
+				discordgo.Button{
+					CustomID: "player_forward",
+					Label:    "Forward",
+					Style:    discordgo.SecondaryButton,
+				},
+				discordgo.Button{
+					CustomID: "player_loop",
+					Label:    "Loop: " + loopMode.String(),
+					Style:    loopStyle,
+				},
+			},
+		},
 	}
 }
 
@@ -2,6 +2,7 @@ package embeds
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
@@ -15,9 +16,9 @@ func NowPlaying(track *audio.Track, session *audio.Session) *discordgo.MessageEm
 	// Create a nicer progress bar
 	progressBar := createProgressBar(elapsed, total)
 	timeDisplay := fmt.Sprintf("`%s`  %s  `%s`",
-		formatDuration(elapsed),
+		FormatDuration(elapsed),
 		progressBar,
-		formatDuration(total),
+		FormatDuration(total),
 	)
 
 	description := fmt.Sprintf("**%s**\n%s\n\n%s",
@@ -50,6 +51,59 @@ func NowPlaying(track *audio.Track, session *audio.Session) *discordgo.MessageEm
 		})
 	}
 
+	if loopMode := session.LoopMode(); loopMode != audio.LoopOff {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "Loop",
+			Value:  loopMode.String(),
+			Inline: true,
+		})
+	}
+
+	if session.Autoplay() {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "Autoplay",
+			Value:  "On",
+			Inline: true,
+		})
+	}
+
+	if filters := session.Filters(); len(filters) > 0 {
+		names := make([]string, len(filters))
+		for i, f := range filters {
+			names[i] = f.String()
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "Filters",
+			Value:  strings.Join(names, ", "),
+			Inline: true,
+		})
+	}
+
+	if rate := session.PlaybackRate(); rate != 1 || session.Pitch() != 0 {
+		value := fmt.Sprintf("%.2fx", rate)
+		if pitch := session.Pitch(); pitch != 0 {
+			value += fmt.Sprintf(", %+.1f st", pitch)
+		}
+		// The bar shows track time, so also say how long is left in real time
+		if total > 0 && rate != 1 {
+			remaining := time.Duration(float64(total-elapsed) / rate)
+			value += fmt.Sprintf(" (%s left)", FormatDuration(remaining))
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "Speed",
+			Value:  value,
+			Inline: true,
+		})
+	}
+
+	if votes, needed := session.SkipVotes(); votes > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "Skip Votes",
+			Value:  fmt.Sprintf("%d of %d", votes, needed),
+			Inline: true,
+		})
+	}
+
 	if track.RequestedBy != "" {
 		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
 			Name:   "Requested by",
@@ -61,6 +115,23 @@ func NowPlaying(track *audio.Track, session *audio.Session) *discordgo.MessageEm
 	return embed
 }
 
+// StreamHealth shows how full the jitter buffer is and any trouble the
+// session has had getting audio out
+func StreamHealth(stats audio.StreamStats) *discordgo.MessageEmbedField {
+	value := fmt.Sprintf("%d/%d frames", stats.Depth, stats.Capacity)
+	if stats.Underruns > 0 {
+		value += fmt.Sprintf(", %d underruns", stats.Underruns)
+	}
+	if stats.Dropped > 0 {
+		value += fmt.Sprintf(", %d dropped", stats.Dropped)
+	}
+	return &discordgo.MessageEmbedField{
+		Name:   "Buffer",
+		Value:  value,
+		Inline: true,
+	}
+}
+
 func createProgressBar(elapsed, total time.Duration) string {
 	const barLength = 16
 
@@ -101,7 +172,8 @@ func repeatString(s string, n int) string {
 	return result
 }
 
-func formatDuration(d time.Duration) string {
+// FormatDuration shows d as m:ss, or h:mm:ss from an hour up
+func FormatDuration(d time.Duration) string {
 	if d < 0 {
 		d = 0
 	}
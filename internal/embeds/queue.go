@@ -58,7 +58,7 @@ func Queue(current *audio.Track, upcoming []*audio.Track, page int) *discordgo.M
 
 		description += fmt.Sprintf("\n\n**Total:** %d tracks | %s",
 			len(upcoming),
-			formatDuration(totalDuration),
+			FormatDuration(totalDuration),
 		)
 	}
 
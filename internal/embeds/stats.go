@@ -0,0 +1,117 @@
+package embeds
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dickeyy/meow/internal/storage"
+)
+
+func Stats(stats *storage.GuildStats, period string) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title: "Listening Stats",
+		Description: fmt.Sprintf("**%s:** %d tracks played, %d skipped, %s listened",
+			period,
+			stats.Plays,
+			stats.Skips,
+			formatListened(stats.Listened),
+		),
+		Color: ColorDefault,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Meow",
+		},
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+
+	if len(stats.TopTracks) > 0 {
+		lines := make([]string, len(stats.TopTracks))
+		for i, t := range stats.TopTracks {
+			title := t.Title
+			if t.URL != "" {
+				title = fmt.Sprintf("[%s](%s)", t.Title, t.URL)
+			}
+			if t.Artist != "" {
+				title += " - " + t.Artist
+			}
+			lines[i] = fmt.Sprintf("`%d.` %s (%s)", i+1, title, plays(t.Plays))
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  "Top Tracks",
+			Value: truncateField(strings.Join(lines, "\n")),
+		})
+	}
+
+	if len(stats.TopArtists) > 0 {
+		lines := make([]string, len(stats.TopArtists))
+		for i, a := range stats.TopArtists {
+			lines[i] = fmt.Sprintf("`%d.` %s (%s)", i+1, a.Artist, plays(a.Plays))
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "Top Artists",
+			Value:  truncateField(strings.Join(lines, "\n")),
+			Inline: true,
+		})
+	}
+
+	if len(stats.TopRequesters) > 0 {
+		lines := make([]string, len(stats.TopRequesters))
+		for i, r := range stats.TopRequesters {
+			lines[i] = fmt.Sprintf("`%d.` <@%s> (%s)", i+1, r.UserID, plays(r.Plays))
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "Top Requesters",
+			Value:  truncateField(strings.Join(lines, "\n")),
+			Inline: true,
+		})
+	}
+
+	return embed
+}
+
+func plays(n int) string {
+	if n == 1 {
+		return "1 play"
+	}
+	return fmt.Sprintf("%d plays", n)
+}
+
+// formatListened writes a long total like 3d 4h 12m
+func formatListened(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+
+	minutes := int(d.Minutes())
+	days := minutes / (24 * 60)
+	hours := minutes / 60 % 24
+	minutes %= 60
+
+	var parts []string
+	if days > 0 {
+		parts = append(parts, fmt.Sprintf("%dd", days))
+	}
+	if hours > 0 {
+		parts = append(parts, fmt.Sprintf("%dh", hours))
+	}
+	if minutes > 0 {
+		parts = append(parts, fmt.Sprintf("%dm", minutes))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Discord rejects embed fields longer than this
+const maxFieldLength = 1024
+
+func truncateField(value string) string {
+	if len(value) <= maxFieldLength {
+		return value
+	}
+	// Cut at a line break so a link isn't left half finished
+	cut := strings.LastIndex(value[:maxFieldLength-4], "\n")
+	if cut < 0 {
+		cut = maxFieldLength - 4
+	}
+	return value[:cut] + "\n..."
+}
@@ -246,6 +246,49 @@ func (c *Client) playlistTrackToAudioTrack(track *spotify.FullTrack, requestedBy
 	return t
 }
 
+// Recommend returns tracks similar to the given Spotify track IDs. Spotify
+// accepts at most five seeds.
+func (c *Client) Recommend(seedIDs []string, limit int, requestedBy string) ([]*audio.Track, error) {
+	if c.client == nil {
+		return nil, fmt.Errorf("spotify client not initialized")
+	}
+
+	if len(seedIDs) > spotify.MaxNumberOfSeeds {
+		seedIDs = seedIDs[len(seedIDs)-spotify.MaxNumberOfSeeds:]
+	}
+
+	seeds := spotify.Seeds{}
+	for _, id := range seedIDs {
+		seeds.Tracks = append(seeds.Tracks, spotify.ID(id))
+	}
+
+	recs, err := c.client.GetRecommendations(c.ctx, seeds, nil, spotify.Limit(limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recommendations: %w", err)
+	}
+
+	tracks := make([]*audio.Track, 0, len(recs.Tracks))
+	for _, item := range recs.Tracks {
+		track := &audio.Track{
+			ID:          string(item.ID),
+			Title:       item.Name,
+			Artist:      artistsToString(item.Artists),
+			Album:       item.Album.Name,
+			Duration:    item.TimeDuration(),
+			Source:      audio.SourceSpotify,
+			RequestedBy: requestedBy,
+		}
+
+		if len(item.Album.Images) > 0 {
+			track.Thumbnail = item.Album.Images[0].URL
+		}
+
+		tracks = append(tracks, track)
+	}
+
+	return tracks, nil
+}
+
 func artistsToString(artists []spotify.SimpleArtist) string {
 	names := make([]string, len(artists))
 	for i, a := range artists {
@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/exec"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -153,6 +154,41 @@ func (e *Extractor) extractPlaylist(url string, requestedBy string) ([]*audio.Tr
 		return nil, err
 	}
 
+	tracks := parseFlatPlaylist(output, requestedBy)
+
+	if len(tracks) == 0 {
+		return nil, fmt.Errorf("no tracks found in playlist")
+	}
+
+	return tracks, nil
+}
+
+// Related returns tracks from YouTube's auto-generated mix for a video,
+// excluding the video itself
+func (e *Extractor) Related(videoID string, limit int, requestedBy string) ([]*audio.Track, error) {
+	mixURL := fmt.Sprintf("https://www.youtube.com/watch?v=%s&list=RD%s", videoID, videoID)
+
+	output, err := e.runCommand(
+		"-j",
+		"--flat-playlist",
+		"--playlist-end", strconv.Itoa(limit+1),
+		mixURL,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var tracks []*audio.Track
+	for _, track := range parseFlatPlaylist(output, requestedBy) {
+		if track.ID != videoID {
+			tracks = append(tracks, track)
+		}
+	}
+
+	return tracks, nil
+}
+
+func parseFlatPlaylist(output []byte, requestedBy string) []*audio.Track {
 	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
 	tracks := make([]*audio.Track, 0, len(lines))
 
@@ -182,11 +218,7 @@ func (e *Extractor) extractPlaylist(url string, requestedBy string) ([]*audio.Tr
 		tracks = append(tracks, track)
 	}
 
-	if len(tracks) == 0 {
-		return nil, fmt.Errorf("no tracks found in playlist")
-	}
-
-	return tracks, nil
+	return tracks
 }
 
 func (e *Extractor) GetStreamURL(track *audio.Track) (string, error) {
@@ -301,16 +333,18 @@ func (e *Extractor) infoToTrack(info *ytdlpOutput, requestedBy string) *audio.Tr
 		title = info.Track
 	}
 
-	return &audio.Track{
+	track := &audio.Track{
 		ID:          info.ID,
 		Title:       title,
 		Artist:      artist,
 		Album:       info.Album,
 		Duration:    time.Duration(info.Duration) * time.Second,
 		URL:         info.WebpageURL,
-		StreamURL:   info.URL,
 		Thumbnail:   info.Thumbnail,
 		Source:      audio.SourceYouTube,
 		RequestedBy: requestedBy,
 	}
+	track.SetStreamURL(info.URL)
+
+	return track
 }
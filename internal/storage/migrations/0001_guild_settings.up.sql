@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS guild_settings (
+	guild_id VARCHAR(255) PRIMARY KEY,
+	default_volume INTEGER DEFAULT 50,
+	dj_role_id VARCHAR(255) DEFAULT '',
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Databases from before versioned migrations may have some of these already
+ALTER TABLE guild_settings ADD COLUMN IF NOT EXISTS eq_presets JSONB DEFAULT '{}';
+ALTER TABLE guild_settings ADD COLUMN IF NOT EXISTS normalize BOOLEAN DEFAULT FALSE;
+ALTER TABLE guild_settings ADD COLUMN IF NOT EXISTS bitrate INTEGER DEFAULT 0;
+ALTER TABLE guild_settings ADD COLUMN IF NOT EXISTS application VARCHAR(16) DEFAULT '';
+ALTER TABLE guild_settings ADD COLUMN IF NOT EXISTS vote_skip_percent INTEGER DEFAULT 0;
+ALTER TABLE guild_settings ADD COLUMN IF NOT EXISTS announce_channel_id VARCHAR(255) DEFAULT '';
+ALTER TABLE guild_settings ADD COLUMN IF NOT EXISTS max_queue_length INTEGER DEFAULT 0;
+ALTER TABLE guild_settings ADD COLUMN IF NOT EXISTS max_track_duration INTEGER DEFAULT 0;
+ALTER TABLE guild_settings ADD COLUMN IF NOT EXISTS idle_timeout INTEGER DEFAULT 0;
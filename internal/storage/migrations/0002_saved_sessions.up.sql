@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS saved_sessions (
+	guild_id VARCHAR(255) PRIMARY KEY,
+	data JSONB NOT NULL,
+	saved_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
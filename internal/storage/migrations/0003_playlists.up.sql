@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS playlists (
+	id BIGSERIAL PRIMARY KEY,
+	name VARCHAR(100) NOT NULL,
+	owner_id VARCHAR(255) NOT NULL,
+	guild_id VARCHAR(255) NOT NULL DEFAULT '',
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Personal playlists are unique per owner, server playlists per server
+CREATE UNIQUE INDEX IF NOT EXISTS playlists_personal_name ON playlists (owner_id, name) WHERE guild_id = '';
+CREATE UNIQUE INDEX IF NOT EXISTS playlists_server_name ON playlists (guild_id, name) WHERE guild_id <> '';
+
+CREATE TABLE IF NOT EXISTS playlist_tracks (
+	id BIGSERIAL PRIMARY KEY,
+	playlist_id BIGINT NOT NULL REFERENCES playlists(id) ON DELETE CASCADE,
+	position INTEGER NOT NULL,
+	track_id VARCHAR(255) DEFAULT '',
+	source VARCHAR(32) DEFAULT '',
+	url TEXT DEFAULT '',
+	title TEXT NOT NULL,
+	artist TEXT DEFAULT '',
+	album TEXT DEFAULT '',
+	duration_ms BIGINT DEFAULT 0,
+	thumbnail TEXT DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS playlist_tracks_position ON playlist_tracks (playlist_id, position);
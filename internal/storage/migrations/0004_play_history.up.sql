@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS play_history (
+	id BIGSERIAL PRIMARY KEY,
+	guild_id VARCHAR(255) NOT NULL,
+	track_id VARCHAR(255) DEFAULT '',
+	title TEXT NOT NULL,
+	artist TEXT DEFAULT '',
+	url TEXT DEFAULT '',
+	source VARCHAR(32) DEFAULT '',
+	duration_ms BIGINT DEFAULT 0,
+	requested_by VARCHAR(255) DEFAULT '',
+	started_at TIMESTAMP NOT NULL,
+	ended_at TIMESTAMP NOT NULL,
+	listened_ms BIGINT DEFAULT 0,
+	skipped BOOLEAN DEFAULT FALSE
+);
+
+CREATE INDEX IF NOT EXISTS play_history_guild_started ON play_history (guild_id, started_at);
@@ -0,0 +1,2 @@
+ALTER TABLE guild_settings DROP COLUMN IF EXISTS packet_loss;
+ALTER TABLE guild_settings DROP COLUMN IF EXISTS fec;
@@ -0,0 +1,3 @@
+-- NULL leaves them at the bot's defaults
+ALTER TABLE guild_settings ADD COLUMN IF NOT EXISTS fec BOOLEAN;
+ALTER TABLE guild_settings ADD COLUMN IF NOT EXISTS packet_loss INTEGER;
@@ -3,20 +3,144 @@ package storage
 import "time"
 
 type GuildSettings struct {
-	GuildID       string    `json:"guild_id"`
-	DefaultVolume int       `json:"default_volume"`
-	DJRoleID      string    `json:"dj_role_id"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	GuildID           string               `json:"guild_id"`
+	DefaultVolume     int                  `json:"default_volume"`
+	DJRoleID          string               `json:"dj_role_id"`
+	EQPresets         map[string][]float64 `json:"eq_presets"`          // Preset name -> gain per band in dB
+	Normalize         bool                 `json:"normalize"`           // EBU R128 loudness normalization
+	Bitrate           int                  `json:"bitrate"`             // Opus bitrate in kbps, 0 for the bot default
+	Application       string               `json:"application"`         // Opus application, empty for the bot default
+	FEC               *bool                `json:"fec"`                 // Opus in-band FEC, nil for the bot default
+	PacketLoss        *int                 `json:"packet_loss"`         // Expected packet loss in percent, nil for the bot default
+	VoteSkipPercent   int                  `json:"vote_skip_percent"`   // Share of listeners needed to skip, 0 to skip without a vote
+	AnnounceChannelID string               `json:"announce_channel_id"` // Where now playing messages go, empty for wherever /play was used
+	MaxQueueLength    int                  `json:"max_queue_length"`    // 0 for no limit
+	MaxTrackDuration  int                  `json:"max_track_duration"`  // Minutes, 0 for no limit
+	IdleTimeout       int                  `json:"idle_timeout"`        // Minutes to stay in voice with nothing playing, 0 to stay
+	CreatedAt         time.Time            `json:"created_at"`
+	UpdatedAt         time.Time            `json:"updated_at"`
 }
 
+// DefaultGuildSettings are the settings of a guild that hasn't changed any of
+// them
 func DefaultGuildSettings(guildID string) *GuildSettings {
 	return &GuildSettings{
 		GuildID:       guildID,
 		DefaultVolume: 50,
 		DJRoleID:      "",
+		EQPresets:     make(map[string][]float64),
 		CreatedAt:     time.Now(),
 		UpdatedAt:     time.Now(),
 	}
 }
 
+// SavedSession is a snapshot of a guild's playback, kept so it can be
+// picked up again after the bot restarts
+type SavedSession struct {
+	GuildID        string        `json:"guild_id"`
+	VoiceChannelID string        `json:"voice_channel_id"`
+	TextChannelID  string        `json:"text_channel_id"`
+	Tracks         []SavedTrack  `json:"tracks"`  // Current track first
+	History        []SavedTrack  `json:"history"` // Oldest first
+	Position       time.Duration `json:"position"`
+	Volume         int           `json:"volume"`
+	LoopMode       string        `json:"loop_mode"`
+	Filters        []string      `json:"filters"`
+	EQ             []float64     `json:"eq"`
+	Normalize      *bool         `json:"normalize,omitempty"` // Nil in sessions saved before it was kept
+	Speed          float64       `json:"speed"`
+	Pitch          float64       `json:"pitch"`
+	Gapless        bool          `json:"gapless"`
+	Crossfade      time.Duration `json:"crossfade"`
+	Autoplay       bool          `json:"autoplay"`
+	SavedAt        time.Time     `json:"saved_at"`
+}
+
+// SavedTrack is the part of a track worth keeping. Stream URLs expire, so
+// tracks are resolved again when the session is resumed.
+type SavedTrack struct {
+	ID          string        `json:"id"`
+	Title       string        `json:"title"`
+	Artist      string        `json:"artist"`
+	Album       string        `json:"album"`
+	Duration    time.Duration `json:"duration"`
+	URL         string        `json:"url"`
+	Thumbnail   string        `json:"thumbnail"`
+	Source      string        `json:"source"`
+	RequestedBy string        `json:"requested_by"`
+	PlaylistID  string        `json:"playlist_id"`
+	Loudness    float64       `json:"loudness"`
+	TruePeak    float64       `json:"true_peak"`
+}
+
+// Playlist is a saved list of tracks. Personal playlists belong to a user
+// and work in any server, server playlists are shared within one guild.
+type Playlist struct {
+	ID         int64        `json:"id"`
+	Name       string       `json:"name"`
+	OwnerID    string       `json:"owner_id"`
+	GuildID    string       `json:"guild_id"` // Empty for personal playlists
+	Tracks     []SavedTrack `json:"tracks"`
+	TrackCount int          `json:"track_count"`
+	CreatedAt  time.Time    `json:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+}
+
+// IsServer reports whether the playlist is shared with a guild rather than
+// belonging to its owner
+func (p *Playlist) IsServer() bool {
+	return p.GuildID != ""
+}
+
+// PlaylistScope says which playlists a lookup by name considers
+type PlaylistScope int
+
+const (
+	// AnyPlaylist prefers the user's own playlist over the server's
+	AnyPlaylist PlaylistScope = iota
+	PersonalPlaylist
+	ServerPlaylist
+)
+
+// PlayRecord is one entry in a guild's play history
+type PlayRecord struct {
+	GuildID     string
+	TrackID     string
+	Title       string
+	Artist      string
+	URL         string
+	Source      string
+	Duration    time.Duration
+	RequestedBy string
+	StartedAt   time.Time
+	EndedAt     time.Time
+	Listened    time.Duration
+	Skipped     bool
+}
+
+// GuildStats summarise a guild's play history since a point in time
+type GuildStats struct {
+	Plays         int
+	Skips         int
+	Listened      time.Duration
+	TopTracks     []TrackCount
+	TopArtists    []ArtistCount
+	TopRequesters []RequesterCount
+}
+
+type TrackCount struct {
+	Title  string
+	Artist string
+	URL    string
+	Plays  int
+}
+
+type ArtistCount struct {
+	Artist string
+	Plays  int
+}
+
+type RequesterCount struct {
+	UserID string
+	Plays  int
+}
@@ -2,9 +2,12 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -13,38 +16,38 @@ type PostgresStore struct {
 	ctx  context.Context
 }
 
+// NewPostgresStore connects to the database and brings its schema up to
+// date
 func NewPostgresStore(ctx context.Context, url string) (*PostgresStore, error) {
-	pool, err := pgxpool.New(ctx, url)
+	store, err := OpenPostgresStore(ctx, url)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create connection pool: %w", err)
-	}
-
-	if err := pool.Ping(ctx); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %w", err)
+		return nil, err
 	}
 
-	store := &PostgresStore{pool: pool, ctx: ctx}
-
-	if err := store.migrate(); err != nil {
+	applied, err := store.MigrateUp()
+	if err != nil {
+		store.Close()
 		return nil, fmt.Errorf("failed to run migrations: %w", err)
 	}
+	for _, m := range applied {
+		fmt.Printf("[storage] Applied migration %04d_%s\n", m.Version, m.Name)
+	}
 
 	return store, nil
 }
 
-func (s *PostgresStore) migrate() error {
-	query := `
-		CREATE TABLE IF NOT EXISTS guild_settings (
-			guild_id VARCHAR(255) PRIMARY KEY,
-			default_volume INTEGER DEFAULT 50,
-			dj_role_id VARCHAR(255) DEFAULT '',
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		);
-	`
+// OpenPostgresStore connects to the database without touching its schema
+func OpenPostgresStore(ctx context.Context, url string) (*PostgresStore, error) {
+	pool, err := pgxpool.New(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create connection pool: %w", err)
+	}
 
-	_, err := s.pool.Exec(s.ctx, query)
-	return err
+	if err := pool.Ping(ctx); err != nil {
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return &PostgresStore{pool: pool, ctx: ctx}, nil
 }
 
 func (s *PostgresStore) Close() {
@@ -53,7 +56,8 @@ func (s *PostgresStore) Close() {
 
 func (s *PostgresStore) GetGuildSettings(guildID string) (*GuildSettings, error) {
 	query := `
-		SELECT guild_id, default_volume, dj_role_id, created_at, updated_at 
+		SELECT guild_id, default_volume, dj_role_id, eq_presets, normalize, bitrate, application, fec, packet_loss, vote_skip_percent,
+			announce_channel_id, max_queue_length, max_track_duration, idle_timeout, created_at, updated_at
 		FROM guild_settings 
 		WHERE guild_id = $1
 	`
@@ -63,13 +67,31 @@ func (s *PostgresStore) GetGuildSettings(guildID string) (*GuildSettings, error)
 		&settings.GuildID,
 		&settings.DefaultVolume,
 		&settings.DJRoleID,
+		&settings.EQPresets,
+		&settings.Normalize,
+		&settings.Bitrate,
+		&settings.Application,
+		&settings.FEC,
+		&settings.PacketLoss,
+		&settings.VoteSkipPercent,
+		&settings.AnnounceChannelID,
+		&settings.MaxQueueLength,
+		&settings.MaxTrackDuration,
+		&settings.IdleTimeout,
 		&settings.CreatedAt,
 		&settings.UpdatedAt,
 	)
 
+	// The caller fills in defaults for guilds that haven't saved anything
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
 	if err != nil {
-		// Return default settings if not found
-		return DefaultGuildSettings(guildID), nil
+		return nil, err
+	}
+
+	if settings.EQPresets == nil {
+		settings.EQPresets = make(map[string][]float64)
 	}
 
 	return settings, nil
@@ -79,11 +101,23 @@ func (s *PostgresStore) SaveGuildSettings(settings *GuildSettings) error {
 	settings.UpdatedAt = time.Now()
 
 	query := `
-		INSERT INTO guild_settings (guild_id, default_volume, dj_role_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO guild_settings (guild_id, default_volume, dj_role_id, eq_presets, normalize, bitrate, application, fec, packet_loss,
+			vote_skip_percent, announce_channel_id, max_queue_length, max_track_duration, idle_timeout, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 		ON CONFLICT (guild_id) DO UPDATE SET
 			default_volume = EXCLUDED.default_volume,
 			dj_role_id = EXCLUDED.dj_role_id,
+			eq_presets = EXCLUDED.eq_presets,
+			normalize = EXCLUDED.normalize,
+			bitrate = EXCLUDED.bitrate,
+			application = EXCLUDED.application,
+			fec = EXCLUDED.fec,
+			packet_loss = EXCLUDED.packet_loss,
+			vote_skip_percent = EXCLUDED.vote_skip_percent,
+			announce_channel_id = EXCLUDED.announce_channel_id,
+			max_queue_length = EXCLUDED.max_queue_length,
+			max_track_duration = EXCLUDED.max_track_duration,
+			idle_timeout = EXCLUDED.idle_timeout,
 			updated_at = EXCLUDED.updated_at
 	`
 
@@ -91,6 +125,17 @@ func (s *PostgresStore) SaveGuildSettings(settings *GuildSettings) error {
 		settings.GuildID,
 		settings.DefaultVolume,
 		settings.DJRoleID,
+		settings.EQPresets,
+		settings.Normalize,
+		settings.Bitrate,
+		settings.Application,
+		settings.FEC,
+		settings.PacketLoss,
+		settings.VoteSkipPercent,
+		settings.AnnounceChannelID,
+		settings.MaxQueueLength,
+		settings.MaxTrackDuration,
+		settings.IdleTimeout,
 		settings.CreatedAt,
 		settings.UpdatedAt,
 	)
@@ -98,3 +143,401 @@ func (s *PostgresStore) SaveGuildSettings(settings *GuildSettings) error {
 	return err
 }
 
+
+func (s *PostgresStore) SaveSession(saved *SavedSession) error {
+	query := `
+		INSERT INTO saved_sessions (guild_id, data, saved_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (guild_id) DO UPDATE SET
+			data = EXCLUDED.data,
+			saved_at = EXCLUDED.saved_at
+	`
+
+	_, err := s.pool.Exec(s.ctx, query, saved.GuildID, saved, saved.SavedAt)
+	return err
+}
+
+func (s *PostgresStore) GetSavedSession(guildID string) (*SavedSession, error) {
+	saved := &SavedSession{}
+	err := s.pool.QueryRow(s.ctx, `SELECT data FROM saved_sessions WHERE guild_id = $1`, guildID).Scan(saved)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+func (s *PostgresStore) GetSavedSessions() ([]*SavedSession, error) {
+	rows, err := s.pool.Query(s.ctx, `SELECT data FROM saved_sessions ORDER BY saved_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*SavedSession
+	for rows.Next() {
+		saved := &SavedSession{}
+		if err := rows.Scan(saved); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, saved)
+	}
+	return sessions, rows.Err()
+}
+
+func (s *PostgresStore) DeleteSavedSession(guildID string) error {
+	_, err := s.pool.Exec(s.ctx, `DELETE FROM saved_sessions WHERE guild_id = $1`, guildID)
+	return err
+}
+
+// ErrPlaylistExists is returned when creating a playlist whose name is
+// already taken by the same owner or server
+var ErrPlaylistExists = errors.New("playlist already exists")
+
+func (s *PostgresStore) CreatePlaylist(playlist *Playlist) error {
+	query := `
+		INSERT INTO playlists (name, owner_id, guild_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $4)
+		RETURNING id
+	`
+
+	now := time.Now()
+	err := s.pool.QueryRow(s.ctx, query, playlist.Name, playlist.OwnerID, playlist.GuildID, now).Scan(&playlist.ID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrPlaylistExists
+		}
+		return err
+	}
+
+	playlist.CreatedAt = now
+	playlist.UpdatedAt = now
+	return nil
+}
+
+// GetPlaylist finds a playlist by name among the user's own and the
+// guild's, as scope allows. With both allowed the user's own wins. Returns
+// nil if none matches.
+func (s *PostgresStore) GetPlaylist(userID, guildID, name string, scope PlaylistScope) (*Playlist, error) {
+	query := `
+		SELECT id, name, owner_id, guild_id, created_at, updated_at
+		FROM playlists
+		WHERE name = $3 AND (
+			(owner_id = $1 AND guild_id = '' AND $4::BOOLEAN) OR
+			(guild_id = $2 AND $5::BOOLEAN)
+		)
+		ORDER BY guild_id = '' DESC
+		LIMIT 1
+	`
+	personal := scope != ServerPlaylist
+	server := scope != PersonalPlaylist
+
+	playlist := &Playlist{}
+	err := s.pool.QueryRow(s.ctx, query, userID, guildID, name, personal, server).Scan(
+		&playlist.ID,
+		&playlist.Name,
+		&playlist.OwnerID,
+		&playlist.GuildID,
+		&playlist.CreatedAt,
+		&playlist.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.pool.Query(s.ctx, `
+		SELECT track_id, source, url, title, artist, album, duration_ms, thumbnail
+		FROM playlist_tracks
+		WHERE playlist_id = $1
+		ORDER BY position
+	`, playlist.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var track SavedTrack
+		var durationMs int64
+		if err := rows.Scan(
+			&track.ID,
+			&track.Source,
+			&track.URL,
+			&track.Title,
+			&track.Artist,
+			&track.Album,
+			&durationMs,
+			&track.Thumbnail,
+		); err != nil {
+			return nil, err
+		}
+		track.Duration = time.Duration(durationMs) * time.Millisecond
+		playlist.Tracks = append(playlist.Tracks, track)
+	}
+	playlist.TrackCount = len(playlist.Tracks)
+
+	return playlist, rows.Err()
+}
+
+// ListPlaylists returns the user's own playlists and the server's, without
+// their tracks
+func (s *PostgresStore) ListPlaylists(userID, guildID string) ([]*Playlist, error) {
+	query := `
+		SELECT p.id, p.name, p.owner_id, p.guild_id, p.created_at, p.updated_at, COUNT(t.id)
+		FROM playlists p
+		LEFT JOIN playlist_tracks t ON t.playlist_id = p.id
+		WHERE (p.owner_id = $1 AND p.guild_id = '') OR p.guild_id = $2
+		GROUP BY p.id
+		ORDER BY p.guild_id = '' DESC, p.name
+	`
+
+	rows, err := s.pool.Query(s.ctx, query, userID, guildID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var playlists []*Playlist
+	for rows.Next() {
+		playlist := &Playlist{}
+		if err := rows.Scan(
+			&playlist.ID,
+			&playlist.Name,
+			&playlist.OwnerID,
+			&playlist.GuildID,
+			&playlist.CreatedAt,
+			&playlist.UpdatedAt,
+			&playlist.TrackCount,
+		); err != nil {
+			return nil, err
+		}
+		playlists = append(playlists, playlist)
+	}
+	return playlists, rows.Err()
+}
+
+// AddPlaylistTracks appends tracks to the end of a playlist
+func (s *PostgresStore) AddPlaylistTracks(playlistID int64, tracks []SavedTrack) error {
+	tx, err := s.pool.Begin(s.ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(s.ctx)
+
+	var next int
+	err = tx.QueryRow(s.ctx, `SELECT COALESCE(MAX(position) + 1, 0) FROM playlist_tracks WHERE playlist_id = $1`, playlistID).Scan(&next)
+	if err != nil {
+		return err
+	}
+
+	if err := insertPlaylistTracks(s.ctx, tx, playlistID, next, tracks); err != nil {
+		return err
+	}
+	return tx.Commit(s.ctx)
+}
+
+// ReplacePlaylistTracks swaps a playlist's tracks for a new list
+func (s *PostgresStore) ReplacePlaylistTracks(playlistID int64, tracks []SavedTrack) error {
+	tx, err := s.pool.Begin(s.ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(s.ctx)
+
+	if _, err := tx.Exec(s.ctx, `DELETE FROM playlist_tracks WHERE playlist_id = $1`, playlistID); err != nil {
+		return err
+	}
+
+	if err := insertPlaylistTracks(s.ctx, tx, playlistID, 0, tracks); err != nil {
+		return err
+	}
+	return tx.Commit(s.ctx)
+}
+
+// RemovePlaylistTrack removes the track at position, counting from 0, and
+// closes the gap. It reports whether there was a track there.
+func (s *PostgresStore) RemovePlaylistTrack(playlistID int64, position int) (bool, error) {
+	tx, err := s.pool.Begin(s.ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(s.ctx)
+
+	tag, err := tx.Exec(s.ctx, `DELETE FROM playlist_tracks WHERE playlist_id = $1 AND position = $2`, playlistID, position)
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	_, err = tx.Exec(s.ctx, `UPDATE playlist_tracks SET position = position - 1 WHERE playlist_id = $1 AND position > $2`, playlistID, position)
+	if err != nil {
+		return false, err
+	}
+
+	if err := touchPlaylist(s.ctx, tx, playlistID); err != nil {
+		return false, err
+	}
+	return true, tx.Commit(s.ctx)
+}
+
+func (s *PostgresStore) DeletePlaylist(playlistID int64) error {
+	_, err := s.pool.Exec(s.ctx, `DELETE FROM playlists WHERE id = $1`, playlistID)
+	return err
+}
+
+func insertPlaylistTracks(ctx context.Context, tx pgx.Tx, playlistID int64, position int, tracks []SavedTrack) error {
+	query := `
+		INSERT INTO playlist_tracks (playlist_id, position, track_id, source, url, title, artist, album, duration_ms, thumbnail)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	batch := &pgx.Batch{}
+	for i, track := range tracks {
+		batch.Queue(query,
+			playlistID,
+			position+i,
+			track.ID,
+			track.Source,
+			track.URL,
+			track.Title,
+			track.Artist,
+			track.Album,
+			track.Duration.Milliseconds(),
+			track.Thumbnail,
+		)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return err
+	}
+
+	return touchPlaylist(ctx, tx, playlistID)
+}
+
+func touchPlaylist(ctx context.Context, tx pgx.Tx, playlistID int64) error {
+	_, err := tx.Exec(ctx, `UPDATE playlists SET updated_at = $2 WHERE id = $1`, playlistID, time.Now())
+	return err
+}
+
+func (s *PostgresStore) RecordPlay(record *PlayRecord) error {
+	query := `
+		INSERT INTO play_history (guild_id, track_id, title, artist, url, source, duration_ms, requested_by, started_at, ended_at, listened_ms, skipped)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+
+	_, err := s.pool.Exec(s.ctx, query,
+		record.GuildID,
+		record.TrackID,
+		record.Title,
+		record.Artist,
+		record.URL,
+		record.Source,
+		record.Duration.Milliseconds(),
+		record.RequestedBy,
+		record.StartedAt,
+		record.EndedAt,
+		record.Listened.Milliseconds(),
+		record.Skipped,
+	)
+	return err
+}
+
+// GetGuildStats summarises the plays in a guild that started after since,
+// with up to limit entries in each top list. A zero since covers all time.
+func (s *PostgresStore) GetGuildStats(guildID string, since time.Time, limit int) (*GuildStats, error) {
+	stats := &GuildStats{}
+
+	var listenedMs int64
+	err := s.pool.QueryRow(s.ctx, `
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE skipped), COALESCE(SUM(listened_ms), 0)
+		FROM play_history
+		WHERE guild_id = $1 AND started_at >= $2
+	`, guildID, since).Scan(&stats.Plays, &stats.Skips, &listenedMs)
+	if err != nil {
+		return nil, err
+	}
+	stats.Listened = time.Duration(listenedMs) * time.Millisecond
+
+	if stats.Plays == 0 {
+		return stats, nil
+	}
+
+	// Tracks are told apart by ID where there is one, since titles differ
+	// between sources
+	rows, err := s.pool.Query(s.ctx, `
+		SELECT MAX(title), MAX(artist), MAX(url), COUNT(*) AS plays
+		FROM play_history
+		WHERE guild_id = $1 AND started_at >= $2
+		GROUP BY CASE WHEN track_id <> '' THEN track_id ELSE LOWER(title) END
+		ORDER BY plays DESC, MAX(started_at) DESC
+		LIMIT $3
+	`, guildID, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var track TrackCount
+		if err := rows.Scan(&track.Title, &track.Artist, &track.URL, &track.Plays); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		stats.TopTracks = append(stats.TopTracks, track)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = s.pool.Query(s.ctx, `
+		SELECT MAX(artist), COUNT(*) AS plays
+		FROM play_history
+		WHERE guild_id = $1 AND started_at >= $2 AND artist <> ''
+		GROUP BY LOWER(artist)
+		ORDER BY plays DESC
+		LIMIT $3
+	`, guildID, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var artist ArtistCount
+		if err := rows.Scan(&artist.Artist, &artist.Plays); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		stats.TopArtists = append(stats.TopArtists, artist)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = s.pool.Query(s.ctx, `
+		SELECT requested_by, COUNT(*) AS plays
+		FROM play_history
+		WHERE guild_id = $1 AND started_at >= $2 AND requested_by <> ''
+		GROUP BY requested_by
+		ORDER BY plays DESC
+		LIMIT $3
+	`, guildID, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var requester RequesterCount
+		if err := rows.Scan(&requester.UserID, &requester.Plays); err != nil {
+			return nil, err
+		}
+		stats.TopRequesters = append(stats.TopRequesters, requester)
+	}
+
+	return stats, rows.Err()
+}
@@ -51,3 +51,49 @@ func (s *RedisStore) Exists(ctx context.Context, key string) (bool, error) {
 	return n > 0, err
 }
 
+// Keys returns every key matching pattern. It uses SCAN, so it is safe on
+// a busy server but may miss keys written while it runs.
+func (s *RedisStore) Keys(ctx context.Context, pattern string) ([]string, error) {
+	var keys []string
+	iter := s.client.Scan(ctx, 0, pattern, 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	return keys, iter.Err()
+}
+
+func (s *RedisStore) Publish(ctx context.Context, channel, message string) error {
+	return s.client.Publish(ctx, channel, message).Err()
+}
+
+// Subscribe delivers the messages published to channel until ctx is done.
+// Dropped connections are re-established, and messages sent meanwhile are
+// lost.
+func (s *RedisStore) Subscribe(ctx context.Context, channel string) <-chan string {
+	pubsub := s.client.Subscribe(ctx, channel)
+	out := make(chan string)
+
+	go func() {
+		defer close(out)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				select {
+				case out <- msg.Payload:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
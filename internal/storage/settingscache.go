@@ -0,0 +1,192 @@
+package storage
+
+import (
+	"container/list"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Guilds whose settings are kept in memory. Past this the least recently
+	// used are dropped.
+	settingsCacheSize = 1000
+
+	// How long settings stay in memory and in Redis. Invalidations keep
+	// instances in step, this only bounds how stale a missed one can leave
+	// them.
+	settingsCacheTTL = 10 * time.Minute
+
+	// Instances announce saved settings here so the others drop their copy
+	settingsInvalidateChannel = "settings:invalidate"
+)
+
+// settingsCache is an in-memory LRU of guild settings. It hands out copies,
+// since callers change the settings they get before saving them.
+type settingsCache struct {
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List // Most recently used at the front
+	size    int
+	ttl     time.Duration
+}
+
+type settingsEntry struct {
+	settings *GuildSettings
+	expires  time.Time
+}
+
+func newSettingsCache(size int, ttl time.Duration) *settingsCache {
+	return &settingsCache{
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+		size:    size,
+		ttl:     ttl,
+	}
+}
+
+func (c *settingsCache) get(guildID string) *GuildSettings {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[guildID]
+	if !ok {
+		return nil
+	}
+	entry := elem.Value.(*settingsEntry)
+	if time.Now().After(entry.expires) {
+		c.order.Remove(elem)
+		delete(c.entries, guildID)
+		return nil
+	}
+
+	c.order.MoveToFront(elem)
+	return entry.settings.clone()
+}
+
+func (c *settingsCache) put(settings *GuildSettings) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &settingsEntry{settings: settings.clone(), expires: time.Now().Add(c.ttl)}
+	if elem, ok := c.entries[settings.GuildID]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[settings.GuildID] = c.order.PushFront(entry)
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*settingsEntry).settings.GuildID)
+	}
+}
+
+func (c *settingsCache) remove(guildID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[guildID]; ok {
+		c.order.Remove(elem)
+		delete(c.entries, guildID)
+	}
+}
+
+func (g *GuildSettings) clone() *GuildSettings {
+	c := *g
+	if g.FEC != nil {
+		fec := *g.FEC
+		c.FEC = &fec
+	}
+	if g.PacketLoss != nil {
+		loss := *g.PacketLoss
+		c.PacketLoss = &loss
+	}
+	if g.EQPresets != nil {
+		c.EQPresets = make(map[string][]float64, len(g.EQPresets))
+		for name, gains := range g.EQPresets {
+			c.EQPresets[name] = append([]float64(nil), gains...)
+		}
+	}
+	return &c
+}
+
+func settingsKey(guildID string) string {
+	return "settings:" + guildID
+}
+
+// cachedSettings looks for a guild's settings in memory, then in Redis
+func (s *Storage) cachedSettings(guildID string) *GuildSettings {
+	if settings := s.settings.get(guildID); settings != nil {
+		return settings
+	}
+	if s.redis == nil {
+		return nil
+	}
+
+	val, err := s.redis.Get(s.ctx, settingsKey(guildID))
+	if err != nil || val == "" {
+		return nil
+	}
+	settings := &GuildSettings{}
+	if err := json.Unmarshal([]byte(val), settings); err != nil {
+		return nil
+	}
+
+	s.settings.put(settings)
+	return settings
+}
+
+// cacheSettings keeps settings loaded from or saved to Postgres. Only rows
+// that exist go to Redis, since the defaults depend on each instance's
+// config.
+func (s *Storage) cacheSettings(settings *GuildSettings, stored bool) {
+	s.settings.put(settings)
+	if s.redis == nil || !stored {
+		return
+	}
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return
+	}
+	if err := s.redis.Set(s.ctx, settingsKey(settings.GuildID), string(data), settingsCacheTTL); err != nil {
+		fmt.Printf("[storage] Failed to cache settings for %s: %v\n", settings.GuildID, err)
+	}
+}
+
+// invalidateSettings tells the other instances a guild's settings changed.
+// Messages are "<instance> <guild>" so an instance can ignore its own.
+func (s *Storage) invalidateSettings(guildID string) {
+	if s.redis == nil {
+		return
+	}
+	if err := s.redis.Publish(s.ctx, settingsInvalidateChannel, s.instanceID+" "+guildID); err != nil {
+		fmt.Printf("[storage] Failed to publish settings invalidation: %v\n", err)
+	}
+}
+
+// watchSettings drops settings from memory when another instance saves
+// them, until ctx is done
+func (s *Storage) watchSettings(ctx context.Context) {
+	messages := s.redis.Subscribe(ctx, settingsInvalidateChannel)
+	for payload := range messages {
+		instance, guildID, ok := strings.Cut(payload, " ")
+		if !ok || instance == s.instanceID {
+			continue
+		}
+		s.settings.remove(guildID)
+	}
+}
+
+func newInstanceID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -2,22 +2,38 @@ package storage
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
 type Storage struct {
-	postgres *PostgresStore
-	redis    *RedisStore
-	ctx      context.Context
+	postgres      *PostgresStore
+	redis         *RedisStore
+	ctx           context.Context
+	cancel        context.CancelFunc
+	defaultVolume int
+	settings      *settingsCache
+	instanceID    string // Tells this instance's settings invalidations apart
 }
 
 func New(ctx context.Context, postgresURL, redisURL string) (*Storage, error) {
-	s := &Storage{ctx: ctx}
+	ctx, cancel := context.WithCancel(ctx)
+	s := &Storage{
+		ctx:           ctx,
+		cancel:        cancel,
+		defaultVolume: DefaultGuildSettings("").DefaultVolume,
+		settings:      newSettingsCache(settingsCacheSize, settingsCacheTTL),
+		instanceID:    newInstanceID(),
+	}
 
 	if postgresURL != "" {
 		pg, err := NewPostgresStore(ctx, postgresURL)
 		if err != nil {
+			cancel()
 			return nil, fmt.Errorf("failed to connect to postgres: %w", err)
 		}
 		s.postgres = pg
@@ -26,15 +42,25 @@ func New(ctx context.Context, postgresURL, redisURL string) (*Storage, error) {
 	if redisURL != "" {
 		r, err := NewRedisStore(ctx, redisURL)
 		if err != nil {
+			if s.postgres != nil {
+				s.postgres.Close()
+			}
+			cancel()
 			return nil, fmt.Errorf("failed to connect to redis: %w", err)
 		}
 		s.redis = r
 	}
 
+	// Other instances sharing the database may change settings we've cached
+	if s.postgres != nil && s.redis != nil {
+		go s.watchSettings(ctx)
+	}
+
 	return s, nil
 }
 
 func (s *Storage) Close() {
+	s.cancel()
 	if s.postgres != nil {
 		s.postgres.Close()
 	}
@@ -43,18 +69,59 @@ func (s *Storage) Close() {
 	}
 }
 
+// Persistent reports whether there is a database to save guild data to
+func (s *Storage) Persistent() bool {
+	return s.postgres != nil
+}
+
+// SetDefaultVolume sets the volume guilds start at until they choose their
+// own
+func (s *Storage) SetDefaultVolume(volume int) {
+	s.defaultVolume = volume
+}
+
+// GetGuildSettings returns a guild's settings, from memory or Redis when
+// they're cached there. Changes to the result aren't seen until it's saved.
 func (s *Storage) GetGuildSettings(guildID string) (*GuildSettings, error) {
 	if s.postgres == nil {
-		return DefaultGuildSettings(guildID), nil
+		return s.defaultGuildSettings(guildID), nil
 	}
-	return s.postgres.GetGuildSettings(guildID)
+	if settings := s.cachedSettings(guildID); settings != nil {
+		return settings, nil
+	}
+
+	settings, err := s.postgres.GetGuildSettings(guildID)
+	if err != nil {
+		return nil, err
+	}
+	stored := settings != nil
+	if !stored {
+		settings = s.defaultGuildSettings(guildID)
+	}
+
+	s.cacheSettings(settings, stored)
+	return settings, nil
+}
+
+func (s *Storage) defaultGuildSettings(guildID string) *GuildSettings {
+	settings := DefaultGuildSettings(guildID)
+	settings.DefaultVolume = s.defaultVolume
+	return settings
 }
 
 func (s *Storage) SaveGuildSettings(settings *GuildSettings) error {
 	if s.postgres == nil {
 		return nil
 	}
-	return s.postgres.SaveGuildSettings(settings)
+	if err := s.postgres.SaveGuildSettings(settings); err != nil {
+		// The cached copy may no longer match the database
+		s.settings.remove(settings.GuildID)
+		return err
+	}
+
+	s.cacheSettings(settings, true)
+	s.invalidateSettings(settings.GuildID)
+	return nil
 }
 
 func (s *Storage) CacheStreamURL(trackID, streamURL string) error {
@@ -72,3 +139,181 @@ func (s *Storage) GetCachedStreamURL(trackID string) (string, error) {
 	return s.redis.Get(s.ctx, "stream:"+trackID)
 }
 
+func (s *Storage) CacheLoudness(trackID string, lufs, truePeak float64) error {
+	if s.redis == nil {
+		return nil
+	}
+	// A track's loudness never changes, so keep it around for a while
+	val := fmt.Sprintf("%.2f %.2f", lufs, truePeak)
+	return s.redis.Set(s.ctx, "loudness:"+trackID, val, 30*24*time.Hour)
+}
+
+// GetCachedLoudness returns a track's measured loudness in LUFS and true peak
+// in dBTP, or 0 loudness if it hasn't been measured. Entries cached before
+// true peaks were kept come back with a peak of 0.
+func (s *Storage) GetCachedLoudness(trackID string) (lufs, truePeak float64, err error) {
+	if s.redis == nil {
+		return 0, 0, nil
+	}
+
+	val, err := s.redis.Get(s.ctx, "loudness:"+trackID)
+	if err != nil || val == "" {
+		return 0, 0, err
+	}
+
+	fields := strings.Fields(val)
+	if len(fields) == 0 {
+		return 0, 0, nil
+	}
+	if lufs, err = strconv.ParseFloat(fields[0], 64); err != nil {
+		return 0, 0, err
+	}
+	if len(fields) > 1 {
+		if truePeak, err = strconv.ParseFloat(fields[1], 64); err != nil {
+			return 0, 0, err
+		}
+	}
+	return lufs, truePeak, nil
+}
+
+// Saved sessions go to Postgres when it's available, otherwise to Redis
+// where they expire after savedSessionTTL
+const savedSessionTTL = 24 * time.Hour
+
+func (s *Storage) SaveSession(saved *SavedSession) error {
+	if s.postgres != nil {
+		return s.postgres.SaveSession(saved)
+	}
+	if s.redis == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return err
+	}
+	return s.redis.Set(s.ctx, "session:"+saved.GuildID, string(data), savedSessionTTL)
+}
+
+// GetSavedSession returns a guild's saved session, or nil if there isn't one
+func (s *Storage) GetSavedSession(guildID string) (*SavedSession, error) {
+	if s.postgres != nil {
+		return s.postgres.GetSavedSession(guildID)
+	}
+	if s.redis == nil {
+		return nil, nil
+	}
+
+	val, err := s.redis.Get(s.ctx, "session:"+guildID)
+	if err != nil || val == "" {
+		return nil, err
+	}
+
+	saved := &SavedSession{}
+	if err := json.Unmarshal([]byte(val), saved); err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+func (s *Storage) GetSavedSessions() ([]*SavedSession, error) {
+	if s.postgres != nil {
+		return s.postgres.GetSavedSessions()
+	}
+	if s.redis == nil {
+		return nil, nil
+	}
+
+	keys, err := s.redis.Keys(s.ctx, "session:*")
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []*SavedSession
+	for _, key := range keys {
+		saved, err := s.GetSavedSession(strings.TrimPrefix(key, "session:"))
+		if err != nil {
+			return nil, err
+		}
+		if saved != nil {
+			sessions = append(sessions, saved)
+		}
+	}
+	return sessions, nil
+}
+
+func (s *Storage) DeleteSavedSession(guildID string) error {
+	if s.postgres != nil {
+		return s.postgres.DeleteSavedSession(guildID)
+	}
+	if s.redis == nil {
+		return nil
+	}
+	return s.redis.Delete(s.ctx, "session:"+guildID)
+}
+
+// ErrNoDatabase is returned by features that only work with Postgres
+var ErrNoDatabase = errors.New("no database configured")
+
+func (s *Storage) CreatePlaylist(playlist *Playlist) error {
+	if s.postgres == nil {
+		return ErrNoDatabase
+	}
+	return s.postgres.CreatePlaylist(playlist)
+}
+
+func (s *Storage) GetPlaylist(userID, guildID, name string, scope PlaylistScope) (*Playlist, error) {
+	if s.postgres == nil {
+		return nil, ErrNoDatabase
+	}
+	return s.postgres.GetPlaylist(userID, guildID, name, scope)
+}
+
+func (s *Storage) ListPlaylists(userID, guildID string) ([]*Playlist, error) {
+	if s.postgres == nil {
+		return nil, ErrNoDatabase
+	}
+	return s.postgres.ListPlaylists(userID, guildID)
+}
+
+func (s *Storage) AddPlaylistTracks(playlistID int64, tracks []SavedTrack) error {
+	if s.postgres == nil {
+		return ErrNoDatabase
+	}
+	return s.postgres.AddPlaylistTracks(playlistID, tracks)
+}
+
+func (s *Storage) ReplacePlaylistTracks(playlistID int64, tracks []SavedTrack) error {
+	if s.postgres == nil {
+		return ErrNoDatabase
+	}
+	return s.postgres.ReplacePlaylistTracks(playlistID, tracks)
+}
+
+func (s *Storage) RemovePlaylistTrack(playlistID int64, position int) (bool, error) {
+	if s.postgres == nil {
+		return false, ErrNoDatabase
+	}
+	return s.postgres.RemovePlaylistTrack(playlistID, position)
+}
+
+func (s *Storage) DeletePlaylist(playlistID int64) error {
+	if s.postgres == nil {
+		return ErrNoDatabase
+	}
+	return s.postgres.DeletePlaylist(playlistID)
+}
+
+func (s *Storage) RecordPlay(record *PlayRecord) error {
+	if s.postgres == nil {
+		return ErrNoDatabase
+	}
+	return s.postgres.RecordPlay(record)
+}
+
+func (s *Storage) GetGuildStats(guildID string, since time.Time, limit int) (*GuildStats, error) {
+	if s.postgres == nil {
+		return nil, ErrNoDatabase
+	}
+	return s.postgres.GetGuildStats(guildID, since, limit)
+}
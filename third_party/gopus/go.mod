@@ -0,0 +1,3 @@
+module layeh.com/gopus
+
+go 1.16
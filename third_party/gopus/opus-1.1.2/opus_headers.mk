@@ -0,0 +1,7 @@
+OPUS_HEAD = \
+include/opus.h \
+include/opus_multistream.h \
+src/opus_private.h \
+src/analysis.h \
+src/mlp.h \
+src/tansig_table.h
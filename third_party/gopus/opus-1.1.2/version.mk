@@ -0,0 +1,2 @@
+# static version string; update manually every release.
+PACKAGE_VERSION = "1.1.2"